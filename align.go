@@ -0,0 +1,182 @@
+package transliterator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Alignment links a token of the source text to its part of the transliteration.
+// Offsets are half-open ranges, given both in bytes and in runes.
+type Alignment struct {
+	Source          string // the token as written in the source text
+	SourceStart     int    // byte offset of Source in the source text
+	SourceEnd       int    // byte offset just past Source in the source text
+	SourceRuneStart int    // rune offset of Source in the source text
+	SourceRuneEnd   int    // rune offset just past Source in the source text
+	Output          string // the part of the transliteration produced by the token
+	OutputStart     int    // byte offset of Output in the transliteration
+	OutputEnd       int    // byte offset just past Output in the transliteration
+	OutputRuneStart int    // rune offset of Output in the transliteration
+	OutputRuneEnd   int    // rune offset just past Output in the transliteration
+	Stage           Stage  // pipeline step that produced Output
+}
+
+// TransliterateAligned transliterates text like Transliterate and aligns every token
+// of the source with its transliteration. A phrase entry spanning several words is a
+// single token. Punctuation merged by the final formatting (a comma of the phrase and
+// one of the source) may belong to two neighbouring tokens.
+func (t *Transliterator) TransliterateAligned(text string, lang Language) (string, []Alignment) {
+	tokens := t.transliterateTokens(text, lang)
+	output, spans := t.render(tokens)
+
+	alignments := make([]Alignment, len(tokens))
+	for i, tok := range tokens {
+		alignments[i] = Alignment{
+			Source:          text[tok.start:tok.end],
+			SourceStart:     tok.start,
+			SourceEnd:       tok.end,
+			SourceRuneStart: runeOffset(text, tok.start),
+			SourceRuneEnd:   runeOffset(text, tok.end),
+			Output:          output[spans[i].start:spans[i].end],
+			OutputStart:     spans[i].start,
+			OutputEnd:       spans[i].end,
+			OutputRuneStart: runeOffset(output, spans[i].start),
+			OutputRuneEnd:   runeOffset(output, spans[i].end),
+			Stage:           tok.stage,
+		}
+	}
+
+	return output, alignments
+}
+
+// outputSpan is the byte range of a token in the transliteration
+type outputSpan struct {
+	start int
+	end   int
+}
+
+// render joins the tokens and applies the final formatting, keeping track of where
+// the output of every token ends up
+func (t *Transliterator) render(tokens []token) (string, []outputSpan) {
+	var joined strings.Builder
+	spans := make([]outputSpan, len(tokens))
+	for i, tok := range tokens {
+		if i > 0 && !tok.attach {
+			joined.WriteString(" ")
+		}
+		spans[i].start = joined.Len()
+		joined.WriteString(tok.output)
+		spans[i].end = joined.Len()
+	}
+
+	output := t.postProcess(joined.String(), spans)
+
+	trimmed := strings.TrimLeftFunc(output, unicode.IsSpace)
+	shift := len(output) - len(trimmed)
+	output = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	for i := range spans {
+		spans[i].start = clamp(spans[i].start-shift, 0, len(output))
+		spans[i].end = clamp(spans[i].end-shift, spans[i].start, len(output))
+	}
+
+	return output, spans
+}
+
+// textEdit replaces the bytes start to end of a text by replacement
+type textEdit struct {
+	start       int
+	end         int
+	replacement string
+}
+
+// postProcess applies the essential post-processing patterns to text and moves the
+// token spans along with the edits each pattern makes
+func (t *Transliterator) postProcess(text string, spans []outputSpan) string {
+	result := text
+
+	for _, processor := range t.minimalRegexes {
+		if !processor.essential {
+			continue
+		}
+
+		var edits []textEdit
+		for _, m := range processor.regex.FindAllStringSubmatchIndex(result, -1) {
+			var replacement string
+			if processor.description == "sentence capitalization" || processor.description == "line capitalization" {
+				replacement = capitalizeLast(result[m[0]:m[1]])
+			} else {
+				replacement = string(processor.regex.ExpandString(nil, processor.replacement, result, m))
+			}
+			edits = append(edits, textEdit{start: m[0], end: m[1], replacement: replacement})
+		}
+		if len(edits) == 0 {
+			continue
+		}
+
+		for i := range spans {
+			spans[i].start = mapOffset(edits, spans[i].start, true)
+			spans[i].end = mapOffset(edits, spans[i].end, false)
+		}
+		result = applyEdits(result, edits)
+	}
+
+	return result
+}
+
+// capitalizeLast upper-cases the first letter of the last space-separated part of match
+func capitalizeLast(match string) string {
+	parts := strings.Split(match, " ")
+	if len(parts) >= 2 {
+		lastPart := parts[len(parts)-1]
+		if len(lastPart) > 0 {
+			parts[len(parts)-1] = strings.ToUpper(lastPart[:1]) + lastPart[1:]
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// applyEdits performs the sorted, non-overlapping edits on text
+func applyEdits(text string, edits []textEdit) string {
+	var result strings.Builder
+	last := 0
+	for _, edit := range edits {
+		result.WriteString(text[last:edit.start])
+		result.WriteString(edit.replacement)
+		last = edit.end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// mapOffset gives the position of offset once edits are applied. An offset inside an
+// edited range is kept at the same distance from the end of the range when it starts a
+// token, and from its start when it ends one, so that the whitespace an edit removes
+// never ends up inside a token.
+func mapOffset(edits []textEdit, offset int, isStart bool) int {
+	shift := 0
+	for _, edit := range edits {
+		if offset <= edit.start {
+			break
+		}
+		if offset >= edit.end {
+			shift += len(edit.replacement) - (edit.end - edit.start)
+			continue
+		}
+		if isStart {
+			return edit.start + shift + max(0, len(edit.replacement)-(edit.end-offset))
+		}
+		return edit.start + shift + min(offset-edit.start, len(edit.replacement))
+	}
+	return offset + shift
+}
+
+// runeOffset converts a byte offset of text into a rune offset
+func runeOffset(text string, byteOffset int) int {
+	return utf8.RuneCountInString(text[:byteOffset])
+}
+
+// clamp limits n to the range lo to hi
+func clamp(n, lo, hi int) int {
+	return max(lo, min(n, hi))
+}
@@ -0,0 +1,61 @@
+package transliterator
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTransliterateAligned(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	// A phrase entry, a comma merged with the one of the phrase, repeated spaces and
+	// a capital after the full stop all change the output around the tokens
+	text := "  يا إلهي ، اسمك  وبذكرك. الله\nكتاب"
+	output, alignments := trans.TransliterateAligned(text, Arabic)
+
+	if output != trans.Transliterate(text, Arabic) {
+		t.Errorf("output = %q, expected the Transliterate output %q", output, trans.Transliterate(text, Arabic))
+	}
+
+	expected := []struct {
+		source string
+		output string
+	}{
+		{"يا إلهي", "yá Iláhí,"},
+		{"،", ""},
+		{"اسمك", "ismuka"},
+		{"وبذكرك.", "wa-bi-dhikrika."},
+		{"الله", "Alláh"},
+		{"كتاب", "kitáb"},
+	}
+	if len(alignments) != len(expected) {
+		t.Fatalf("got %d alignments, expected %d: %+v", len(alignments), len(expected), alignments)
+	}
+
+	for i, want := range expected {
+		a := alignments[i]
+		if a.Source != want.source || a.Output != want.output {
+			t.Errorf("alignment %d = %q → %q, expected %q → %q", i, a.Source, a.Output, want.source, want.output)
+		}
+		if text[a.SourceStart:a.SourceEnd] != a.Source {
+			t.Errorf("alignment %d source span %d:%d does not cover %q", i, a.SourceStart, a.SourceEnd, a.Source)
+		}
+		if output[a.OutputStart:a.OutputEnd] != a.Output {
+			t.Errorf("alignment %d output span %d:%d does not cover %q", i, a.OutputStart, a.OutputEnd, a.Output)
+		}
+		if string([]rune(text)[a.SourceRuneStart:a.SourceRuneEnd]) != a.Source {
+			t.Errorf("alignment %d source runes %d:%d do not cover %q", i, a.SourceRuneStart, a.SourceRuneEnd, a.Source)
+		}
+		if string([]rune(output)[a.OutputRuneStart:a.OutputRuneEnd]) != a.Output {
+			t.Errorf("alignment %d output runes %d:%d do not cover %q", i, a.OutputRuneStart, a.OutputRuneEnd, a.Output)
+		}
+	}
+
+	last := alignments[len(alignments)-1]
+	if last.OutputEnd != len(output) || last.OutputRuneEnd != utf8.RuneCountInString(output) {
+		t.Errorf("last token ends at %d (%d runes), expected the end of %q", last.OutputEnd, last.OutputRuneEnd, output)
+	}
+}
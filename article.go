@@ -0,0 +1,309 @@
+package transliterator
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// definiteArticle is the parsed article_rules.definite_article section of a dictionary
+type definiteArticle struct {
+	Pattern             string `json:"pattern"`
+	MoonLetters         string `json:"moon_letters"`
+	SunLetters          string `json:"sun_letters"`
+	MoonTransliteration string `json:"moon_transliteration"`
+	SunTransliteration  string `json:"sun_transliteration"`
+
+	prepositions []prepositionArticle
+}
+
+// prepositionArticle is a parsed article_rules.preposition_article entry
+type prepositionArticle struct {
+	spelling string // Arabic spelling of the preposition
+	attached bool   // written together with the article, as in وال
+	form     string // preposition before the elided article, e.g. "mina" in mina'l-
+}
+
+// prepositionSpellings gives the Arabic spelling of each preposition_article key.
+// Attached prepositions are written as one word with the article (وال، بال، لل);
+// the others are separate words.
+var prepositionSpellings = map[string]struct {
+	spelling string
+	attached bool
+}{
+	"wa_al":  {"و", true},
+	"bi_al":  {"ب", true},
+	"li_al":  {"ل", true},
+	"ka_al":  {"ك", true},
+	"fi_al":  {"في", false},
+	"min_al": {"من", false},
+	"ila_al": {"إلى", false},
+	"ala_al": {"على", false},
+	"an_al":  {"عن", false},
+}
+
+// elidedArticleSuffix ends every preposition_article form
+const elidedArticleSuffix = "'l-"
+
+// parseDefiniteArticle extracts the definite article rule from a dictionary, if it has one
+func parseDefiniteArticle(dict *Dictionary) *definiteArticle {
+	raw, exists := dict.ArticleRules["definite_article"]
+	if !exists {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+
+	article := &definiteArticle{}
+	if err := json.Unmarshal(data, article); err != nil || article.Pattern == "" {
+		return nil
+	}
+
+	if forms, ok := dict.ArticleRules["preposition_article"].(map[string]interface{}); ok {
+		for key, value := range forms {
+			form, isString := value.(string)
+			spelling, known := prepositionSpellings[key]
+			if !isString || !known || !strings.HasSuffix(form, elidedArticleSuffix) {
+				continue
+			}
+			article.prepositions = append(article.prepositions, prepositionArticle{
+				spelling: spelling.spelling,
+				attached: spelling.attached,
+				form:     strings.TrimSuffix(form, elidedArticleSuffix),
+			})
+		}
+		// Longest spelling first so that e.g. في is preferred over ف
+		sort.Slice(article.prepositions, func(i, j int) bool {
+			a, b := article.prepositions[i], article.prepositions[j]
+			if len(a.spelling) != len(b.spelling) {
+				return len(a.spelling) > len(b.spelling)
+			}
+			return a.spelling < b.spelling
+		})
+	}
+
+	return article
+}
+
+// isSunLetter reports whether the article assimilates to r
+func (a *definiteArticle) isSunLetter(r rune) bool {
+	return strings.ContainsRune(a.SunLetters, r)
+}
+
+// prefixFor returns the transliterated article before a word starting with r
+func (a *definiteArticle) prefixFor(r rune, letterMap map[rune]string) string {
+	if a.isSunLetter(r) {
+		if letter, exists := letterMap[r]; exists {
+			return strings.ReplaceAll(a.SunTransliteration, "{letter}", letter)
+		}
+	}
+	return a.MoonTransliteration
+}
+
+// splitPrefix removes prefix from the start of word, ignoring and dropping the
+// diacritics that belong to the prefix letters
+func (t *Transliterator) splitPrefix(word, prefix string) (rest string, ok bool) {
+	pattern := []rune(prefix)
+	runes := []rune(word)
+
+	matched := 0
+	i := 0
+	for ; i < len(runes) && matched < len(pattern); i++ {
+		if _, isMark := t.vowelMarks[runes[i]]; isMark {
+			continue
+		}
+		// Alef wasla (ٱ) of Quranic spelling stands for a plain alef
+		if r := runes[i]; r != pattern[matched] && !(r == 'ٱ' && pattern[matched] == 'ا') {
+			return "", false
+		}
+		matched++
+	}
+	if matched < len(pattern) {
+		return "", false
+	}
+
+	// Skip the marks that belong to the prefix itself (e.g. sukun on the lam)
+	for i < len(runes) {
+		if _, isMark := t.vowelMarks[runes[i]]; !isMark {
+			break
+		}
+		i++
+	}
+
+	return string(runes[i:]), true
+}
+
+// splitArticle separates a leading definite article from word, keeping the
+// diacritics of the stem. It returns ok=false when word does not start with the article.
+func (t *Transliterator) splitArticle(word string, article *definiteArticle) (stem string, ok bool) {
+	stem, ok = t.splitPrefix(word, article.Pattern)
+
+	// A bare article or a single letter after it is not an article construction
+	if !ok || len([]rune(t.removeDiacritics(stem))) < 2 {
+		return "", false
+	}
+
+	// A shadda on the lam itself marks a doubled lam, as in الَّذِي (alladhí)
+	if prefix := []rune(word[:len(word)-len(stem)]); len(prefix) > 0 && strings.ContainsRune(string(prefix[1:]), shadda) {
+		return "", false
+	}
+
+	return stem, true
+}
+
+// analyzeArticle transliterates a word beginning with the definite article,
+// assimilating the article to sun letters as described by the dictionary rules
+func (t *Transliterator) analyzeArticle(word string, lang Language) string {
+	article := t.articles[lang]
+	if article == nil {
+		return ""
+	}
+
+	stem, ok := t.splitArticle(word, article)
+	if !ok {
+		return ""
+	}
+
+	cleanStem := t.removeDiacritics(stem)
+	prefix := article.prefixFor([]rune(cleanStem)[0], t.letterMap(lang))
+
+	entry, _, exists := t.lookupWord(cleanStem, lang)
+	if article.isSunLetter([]rune(cleanStem)[0]) {
+		// The assimilated article already doubles the sun letter (ad-dunyá)
+		stem = t.dropInitialShadda(stem)
+	}
+	if t.isFullyVocalized(stem) {
+		reading := t.readVocalized(stem, t.letterMap(lang), false)
+		if exists {
+			reading = matchCase(reading, entry.Transliteration)
+		}
+		return prefix + reading
+	}
+	if exists {
+		return prefix + entry.Transliteration
+	}
+
+	return prefix + t.dictionaryGuidedHeuristic(stem, t.dictionary(lang), lang)
+}
+
+// dropInitialShadda removes a shadda written on the first letter of word
+func (t *Transliterator) dropInitialShadda(word string) string {
+	runes := []rune(word)
+	for i := 1; i < len(runes); i++ {
+		if _, isMark := t.vowelMarks[runes[i]]; !isMark {
+			break
+		}
+		if runes[i] == shadda {
+			return string(runes[:i]) + string(runes[i+1:])
+		}
+	}
+	return word
+}
+
+// analyzePrepositionArticle transliterates a word made of an attached preposition
+// and the definite article, such as والله (wa'lláh) or بالاسم (bi'l-ism)
+func (t *Transliterator) analyzePrepositionArticle(word string, lang Language) string {
+	article := t.articles[lang]
+	if article == nil {
+		return ""
+	}
+
+	for _, prep := range article.prepositions {
+		if !prep.attached {
+			continue
+		}
+		rest, ok := t.splitPrefix(word, prep.spelling)
+		if !ok {
+			continue
+		}
+
+		for _, candidate := range t.articleRemainders(prep, rest, article) {
+			// Only accept the split when the remainder really is an article construction
+			_, _, known := t.lookupWord(t.removeDiacritics(candidate), lang)
+			if _, isArticle := t.splitArticle(candidate, article); !known && !isArticle {
+				continue
+			}
+
+			if elided, ok := t.elidedArticle(candidate, t.transliterateWordV2(candidate, lang), article); ok {
+				return prep.form + elided
+			}
+		}
+	}
+
+	return ""
+}
+
+// articleRemainders lists the possible article words left after removing an attached
+// preposition. li + al drops the alif of the article (للعالمين), and before the lam
+// of الله the article lam merges as well (لله).
+func (t *Transliterator) articleRemainders(prep prepositionArticle, rest string, article *definiteArticle) []string {
+	if prep.spelling != "ل" || strings.HasPrefix(t.removeDiacritics(rest), article.Pattern) {
+		return []string{rest}
+	}
+
+	pattern := []rune(article.Pattern)
+	if !strings.HasPrefix(t.removeDiacritics(rest), string(pattern[1])) {
+		return []string{string(pattern[0]) + rest}
+	}
+	return []string{string(pattern[0]) + rest, article.Pattern + rest}
+}
+
+// elidedArticle returns output with the alif of the article elided ('l-, 'd-, 'lláh)
+// when source starts with the plain article. Articles written with hamza (أل) keep their alif.
+func (t *Transliterator) elidedArticle(source, output string, article *definiteArticle) (string, bool) {
+	clean := strings.Replace(t.removeDiacritics(source), "ٱ", "ا", 1)
+	if !strings.HasPrefix(clean, article.Pattern) {
+		return "", false
+	}
+
+	runes := []rune(output)
+	if len(runes) < 3 || (runes[0] != 'a' && runes[0] != 'A') || isVowel(unicode.ToLower(runes[1])) {
+		return "", false
+	}
+
+	return "'" + string(runes[1:]), true
+}
+
+// applyArticleElision joins an article to a preceding vowel-final word, e.g.
+// anta al-Muqtadir becomes anta'l-Muqtadir and fí ad-dunyá becomes fí'd-dunyá.
+// Separate prepositions take their preposition_article form first (min → mina'l-).
+func (t *Transliterator) applyArticleElision(tokens []token, lang Language) []token {
+	article := t.articles[lang]
+	if article == nil {
+		return tokens
+	}
+
+	for i := 1; i < len(tokens); i++ {
+		elided, ok := t.elidedArticle(tokens[i].source, tokens[i].output, article)
+		if !ok {
+			continue
+		}
+
+		prev := &tokens[i-1]
+		prevClean := t.removeDiacritics(prev.source)
+		for _, prep := range article.prepositions {
+			if !prep.attached && prevClean == prep.spelling {
+				prev.output = prep.form
+				break
+			}
+		}
+
+		if !endsWithVowel(prev.output) {
+			continue
+		}
+		tokens[i].output = elided
+		tokens[i].attach = true
+	}
+
+	return tokens
+}
+
+// endsWithVowel reports whether a transliteration ends in a vowel
+func endsWithVowel(s string) bool {
+	runes := []rune(s)
+	return len(runes) > 0 && isVowel(unicode.ToLower(runes[len(runes)-1]))
+}
@@ -0,0 +1,148 @@
+package transliterator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefiniteArticle(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests, err := loadTestCases("test_cases/arabic_test_cases.json")
+	if err != nil {
+		t.Fatalf("Failed to load Arabic test cases: %v", err)
+	}
+	if len(tests.ArticleTestWords) == 0 {
+		t.Fatalf("No article test words found in Arabic test cases")
+	}
+
+	for _, tt := range tests.ArticleTestWords {
+		t.Run(tt.Word, func(t *testing.T) {
+			if got := trans.Transliterate(tt.Word, Arabic); got != tt.Expected {
+				t.Errorf("Article handling failed for %s (%s)\nExpected: %s\nGot: %s",
+					tt.Word, tt.Notes, tt.Expected, got)
+			}
+		})
+	}
+}
+
+func TestDefiniteArticleRulesFromDictionary(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	article := trans.articles[Arabic]
+	if article == nil {
+		t.Fatalf("Arabic dictionary article_rules were not parsed")
+	}
+	if trans.articles[Persian] != nil {
+		t.Errorf("Persian dictionary has no article rules, but one was parsed")
+	}
+
+	for _, r := range article.SunLetters {
+		prefix := article.prefixFor(r, trans.arabicLetters)
+		if prefix != "a"+trans.arabicLetters[r]+"-" {
+			t.Errorf("Sun letter %c should assimilate, got %s", r, prefix)
+		}
+	}
+	for _, r := range article.MoonLetters {
+		if prefix := article.prefixFor(r, trans.arabicLetters); prefix != "al-" {
+			t.Errorf("Moon letter %c should keep al-, got %s", r, prefix)
+		}
+	}
+
+	// Words that merely start with alif-lam letters are left alone
+	if _, ok := trans.splitArticle("ال", article); ok {
+		t.Errorf("Bare article should not be split")
+	}
+}
+
+func TestArticleElision(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"attached wa", "والله", "wa'lláh"},
+		{"attached bi", "بالله", "bi'lláh"},
+		{"attached li drops alif", "لله", "li'lláh"},
+		{"attached wa with sun letter", "وَالدُّنْيا", "wa'd-dunyá"},
+		{"after vowel-final word", "أنت المقتدر", "anta'l-Muqtadir"},
+		{"after vowel-final pronoun", "لك الحمد", "laka'l-ḥamdu"},
+		{"after fí with sun letter", "في الدنيا", "fí'd-dunyá"},
+		{"min takes its helping vowel", "من الله", "mina'lláh"},
+		{"'an takes its helping vowel", "عن الاسم", "'ani'l-ism"},
+		{"after consonant-final word", "حب الله", "ḥubb Alláh"},
+		{"no elision across punctuation", "يا إلهي ، الله", "yá Iláhí, Alláh"},
+		{"comma after the phrase", "يا إلهي، الله", "yá Iláhí, Alláh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trans.Transliterate(tt.input, Arabic); got != tt.expected {
+				t.Errorf("Elision failed for %s\nExpected: %s\nGot: %s", tt.input, tt.expected, got)
+			}
+		})
+	}
+
+	// An article written with hamzat al-qat' (أل) keeps its alif
+	if got := trans.Transliterate("لَكَ أَلْحَمْدُ", Arabic); strings.Contains(got, "'") || !strings.HasPrefix(got, "laka ") {
+		t.Errorf("Article with hamza should stay separate, got %s", got)
+	}
+}
+
+func TestArticleElisionInFixtures(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests, err := loadTestCases("test_cases/arabic_test_cases.json")
+	if err != nil {
+		t.Fatalf("Failed to load Arabic test cases: %v", err)
+	}
+	fixtures := make(map[string]TestCase)
+	for _, tc := range tests.TestCases {
+		fixtures[tc.Name] = tc
+	}
+
+	// Passages of the prayers whose expected text elides the article
+	passages := []struct {
+		fixture  string
+		input    string
+		expected string
+	}{
+		{"Short Prayer - Test Case 1", "فِي الدُّنْيا وَالآخِرَةِ", "fí'd-dunyá wa'l-ákhirati"},
+		{"Short Obligatory Prayer - Test Case 2", "أنت المقتدر", "anta'l-Muqtadir"},
+		{"Prayer of Gratitude - Test Case 3", "فَقْدِها الشُّكْرُ", "faqdihá'sh-shukru"},
+		{"Prayer of Gratitude - Test Case 3", "يا مَقْصُودَ العَارِفينَ", "yá Maqṣúda'l-'árifín"},
+		{"Prayer for Purification - Test Case 5", "فِي هذا الحِين", "fí hádhá'l-ḥíni"},
+		{"Prayer for Purification - Test Case 5", "أَنْتَ المُهَيْمِنُ القَيُّومُ", "anta'l-Muhayminu'l-Qayyúm"},
+	}
+
+	for _, p := range passages {
+		t.Run(p.expected, func(t *testing.T) {
+			fixture, ok := fixtures[p.fixture]
+			if !ok || !strings.Contains(fixture.Input, p.input) || !strings.Contains(fixture.Expected, p.expected) {
+				t.Fatalf("%s no longer has %s → %s", p.fixture, p.input, p.expected)
+			}
+			if got := trans.Transliterate(p.input, Arabic); got != p.expected {
+				t.Errorf("Elision failed for %s\nExpected: %s\nGot: %s", p.input, p.expected, got)
+			}
+		})
+	}
+
+	// The name 'Abdu'l-Bahá, whose first letter is capitalized as a name
+	if got := capitalize(trans.Transliterate("عَبْدُ البهاء", Arabic)); got != "'Abdu'l-Bahá" {
+		t.Errorf("Elision failed for 'Abdu'l-Bahá, got %s", got)
+	}
+}
@@ -0,0 +1,185 @@
+package transliterator
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// proclitic is an Arabic conjunction or preposition written together with the next word
+type proclitic struct {
+	spelling        string
+	transliteration string
+	preposition     bool // governs the genitive, as in bi-dhikrika
+}
+
+// cliticSplit is one way of separating proclitics from the start of a word
+type cliticSplit struct {
+	prefixes []proclitic
+	rest     string
+}
+
+// hamzaSeats are the carriers a final hamza moves onto before a suffix (عطائك ← عطاء)
+var hamzaSeats = []string{"ئ", "ؤ", "أ"}
+
+// parseProclitics reads the proclitics section of a dictionary. Entries with category
+// "preposition" govern the genitive; all others are treated as conjunctions.
+func parseProclitics(dict *Dictionary) []proclitic {
+	var result []proclitic
+	for spelling, entry := range dict.Proclitics {
+		if spelling == "" || entry.Transliteration == "" {
+			continue
+		}
+		result = append(result, proclitic{
+			spelling:        spelling,
+			transliteration: entry.Transliteration,
+			preposition:     entry.Category == "preposition",
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].spelling < result[j].spelling
+	})
+
+	return result
+}
+
+// cliticSegmentation is the result of splitting an Arabic word into its clitics
+type cliticSegmentation struct {
+	prefixes    []proclitic
+	stemTrans   string // transliteration of the dictionary stem
+	suffixTrans string // transliteration of the pronominal suffix, if any
+}
+
+// analyzeClitics segments an Arabic word into proclitics (wa-, bi-, ...), a dictionary
+// stem and a pronominal suffix (-ka, -hum, -í), e.g. وبذكرك becomes wa-bi-dhikrika
+func (t *Transliterator) analyzeClitics(word string) string {
+	segmentation, ok := t.segmentClitics(word)
+	if !ok {
+		return ""
+	}
+	return joinClitics(segmentation.prefixes, segmentation.stemTrans, segmentation.suffixTrans)
+}
+
+// segmentClitics finds the clitics of a diacritic-free word. Splits are only accepted
+// when the stem is in the dictionary.
+func (t *Transliterator) segmentClitics(word string) (cliticSegmentation, bool) {
+	for _, split := range t.procliticSplits(word) {
+		for _, rule := range t.suffixes[Arabic] {
+			if !strings.HasSuffix(split.rest, rule.suffix) {
+				continue
+			}
+			stem := strings.TrimSuffix(split.rest, rule.suffix)
+			if stemTrans, ok := t.cliticStem(stem); ok {
+				return cliticSegmentation{split.prefixes, stemTrans, rule.transliteration}, true
+			}
+		}
+
+		if len(split.prefixes) == 0 {
+			continue
+		}
+		if stemTrans, ok := t.cliticStem(split.rest); ok {
+			return cliticSegmentation{split.prefixes, stemTrans, ""}, true
+		}
+	}
+
+	return cliticSegmentation{}, false
+}
+
+// procliticSplits lists the ways to strip an optional conjunction followed by an
+// optional preposition from word, fewest proclitics first
+func (t *Transliterator) procliticSplits(word string) []cliticSplit {
+	splits := []cliticSplit{{rest: word}}
+
+	for _, wantPreposition := range []bool{false, true} {
+		for _, split := range splits {
+			// A conjunction never follows a preposition (وب but not بو)
+			if n := len(split.prefixes); n > 0 && split.prefixes[n-1].preposition {
+				continue
+			}
+			for _, p := range t.proclitics[Arabic] {
+				if p.preposition != wantPreposition || !strings.HasPrefix(split.rest, p.spelling) {
+					continue
+				}
+				rest := strings.TrimPrefix(split.rest, p.spelling)
+				if utf8.RuneCountInString(rest) < 2 {
+					continue
+				}
+				prefixes := append(append([]proclitic(nil), split.prefixes...), p)
+				splits = append(splits, cliticSplit{prefixes: prefixes, rest: rest})
+			}
+		}
+	}
+
+	sort.SliceStable(splits, func(i, j int) bool {
+		return len(splits[i].prefixes) < len(splits[j].prefixes)
+	})
+
+	return splits
+}
+
+// cliticStem transliterates a stem left after removing clitics. Before a suffix the
+// ta marbuta is written ت (رحمتك) and a final hamza sits on a carrier (عطائك), so those
+// spellings are looked up in their dictionary form.
+func (t *Transliterator) cliticStem(stem string) (string, bool) {
+	if utf8.RuneCountInString(stem) < 2 {
+		return "", false
+	}
+
+	if entry, _, exists := t.lookupWord(stem, Arabic); exists {
+		return entry.Transliteration, true
+	}
+
+	if base := strings.TrimSuffix(stem, "ت"); base != stem {
+		if entry, _, exists := t.lookupWord(base+"ة", Arabic); exists {
+			return t.taMarbutaForm(entry.Transliteration, Arabic, true), true
+		}
+	}
+
+	for _, seat := range hamzaSeats {
+		if base := strings.TrimSuffix(stem, seat); base != stem {
+			if entry, _, exists := t.lookupWord(base+"ء", Arabic); exists {
+				trans := entry.Transliteration
+				if !strings.HasSuffix(trans, "'") {
+					trans += "'"
+				}
+				return trans, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// joinClitics assembles proclitics, stem and suffix in house style: proclitics are
+// hyphenated (wa-bi-) and a consonant-final stem takes a case vowel before a suffix,
+// genitive i after a preposition and nominative u otherwise (bi-dhikrika, wa-dhikruka)
+func joinClitics(prefixes []proclitic, stemTrans, suffixTrans string) string {
+	var result strings.Builder
+	for _, p := range prefixes {
+		result.WriteString(p.transliteration + "-")
+	}
+	result.WriteString(stemTrans)
+
+	if suffixTrans == "" {
+		return result.String()
+	}
+
+	vowel := ""
+	if !startsWithVowel(suffixTrans) && !endsWithVowel(stemTrans) {
+		vowel = "u"
+		if len(prefixes) > 0 && prefixes[len(prefixes)-1].preposition {
+			vowel = "i"
+		}
+	}
+	result.WriteString(vowel)
+
+	// The pronoun of the third person harmonizes with a preceding i (bi-ismihi)
+	preceding, _ := utf8.DecodeLastRuneInString(stemTrans + vowel)
+	if strings.HasPrefix(suffixTrans, "hu") && (preceding == 'i' || preceding == 'í') {
+		suffixTrans = "hi" + strings.TrimPrefix(suffixTrans, "hu")
+	}
+	result.WriteString(suffixTrans)
+
+	return result.String()
+}
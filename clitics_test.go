@@ -0,0 +1,37 @@
+package transliterator
+
+import "testing"
+
+func TestArabicClitics(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"وبأمواج", "wa-bi-amwáj"}, // conjunction + preposition
+		{"رحمتك", "raḥmatuka"},     // ta marbuta before a suffix
+		{"عطائك", "'aṭá'uka"},      // hamza on a carrier before a suffix
+		{"وذكرك", "wa-dhikruka"},   // nominative case vowel
+		{"بذكرك", "bi-dhikrika"},   // genitive after a preposition
+		{"لعرفانك", "li-'irfánika"},
+		{"شفائي", "shifá'í"},   // no case vowel before -í
+		{"باسمه", "bi-ismihi"}, // -hu harmonizes with i
+		{"وبرحمتهم", "wa-bi-raḥmatihim"},
+		{"وحب", "wa-ḥubb"},
+	}
+
+	for _, tt := range tests {
+		if got := trans.Transliterate(tt.input, Arabic); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Stems that are not in the dictionary are not split
+	if got := trans.analyzeClitics("بحر"); got != "" {
+		t.Errorf("Expected no segmentation of an unknown stem, got %q", got)
+	}
+}
@@ -1,20 +1,21 @@
 package main
 
 import (
-	"encoding/csv"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"regexp"
 	"strings"
 
 	"github.com/LaPingvino/bahai-transliterator"
+	"github.com/LaPingvino/bahai-transliterator/store"
+	_ "github.com/go-sql-driver/mysql"
 )
 
 type Config struct {
 	DatabasePath string
+	DSN          string
 	DryRun       bool
 	BatchSize    int
 	Language     string
@@ -24,6 +25,7 @@ type FixRecord struct {
 	Version         string
 	SourceID        string
 	Language        string
+	SourceText      string
 	OriginalText    string
 	CleanedText     string
 	HasMixedChars   bool
@@ -34,14 +36,16 @@ func main() {
 	var config Config
 	
 	flag.StringVar(&config.DatabasePath, "db", "", "Path to the bahaiwritings database directory")
+	flag.StringVar(&config.DSN, "dsn", "", "MySQL data source name of a running server such as dolt sql-server, instead of -db")
 	flag.BoolVar(&config.DryRun, "dry-run", false, "Show what would be fixed without making changes")
 	flag.IntVar(&config.BatchSize, "batch-size", 20, "Number of records to process in each batch")
 	flag.StringVar(&config.Language, "lang", "both", "Language to fix: 'fa-translit', 'ar-translit', or 'both'")
 	flag.Parse()
 
-	if config.DatabasePath == "" {
+	if config.DatabasePath == "" && config.DSN == "" {
 		fmt.Println("Usage: fix_mixed_chars -db /path/to/bahaiwritings")
 		fmt.Println("  -db string       Path to the bahaiwritings database directory")
+		fmt.Println("  -dsn string      MySQL data source name of a running server such as dolt sql-server, instead of -db")
 		fmt.Println("  -dry-run         Show what would be fixed without making changes")
 		fmt.Println("  -batch-size int  Number of records to process in each batch (default 20)")
 		fmt.Println("  -lang string     Language to fix: 'fa-translit', 'ar-translit', or 'both' (default 'both')")
@@ -54,12 +58,23 @@ func main() {
 }
 
 func fixMixedCharacters(config Config) error {
-	fmt.Printf("Analyzing transliterations for mixed characters in database: %s\n", config.DatabasePath)
+	// Commits are pushed to the remote
+	writings, err := store.Open(config.DatabasePath, config.DSN, true)
+	if err != nil {
+		return err
+	}
+	defer writings.Close()
+
+	if config.DSN != "" {
+		fmt.Println("Analyzing transliterations for mixed characters through the SQL server")
+	} else {
+		fmt.Printf("Analyzing transliterations for mixed characters in database: %s\n", config.DatabasePath)
+	}
 	fmt.Printf("Language filter: %s\n", config.Language)
 	fmt.Printf("Dry run: %t\n", config.DryRun)
 
 	// Get all transliteration records
-	records, err := getTransliterationRecords(config.DatabasePath, config.Language)
+	records, err := getTransliterationRecords(writings, config.Language)
 	if err != nil {
 		return fmt.Errorf("failed to get records: %v", err)
 	}
@@ -114,12 +129,8 @@ func fixMixedCharacters(config Config) error {
 			fmt.Printf("Processing batch %d-%d...\n", i+1, min(i+config.BatchSize, len(problemRecords)))
 		}
 
-		// Get the original text and re-transliterate it properly
-		originalText, err := getOriginalText(config.DatabasePath, record.SourceID, record.Language)
-		if err != nil {
-			fmt.Printf("  Warning: Could not get original text for %s: %v\n", record.SourceID, err)
-			continue
-		}
+		// Re-transliterate the original text properly
+		originalText := record.SourceText
 
 		var lang transliterator.Language
 		if strings.HasPrefix(record.Language, "fa") {
@@ -135,7 +146,7 @@ func fixMixedCharacters(config Config) error {
 		cleanedTranslit := cleanMixedCharacters(newTranslit)
 
 		fmt.Printf("  Updating %s\n", record.SourceID)
-		if err := updateRecord(config.DatabasePath, record.Version, cleanedTranslit); err != nil {
+		if err := writings.UpdateText(record.Version, cleanedTranslit); err != nil {
 			return fmt.Errorf("failed to update record %s: %v", record.Version, err)
 		}
 		updatedCount++
@@ -145,7 +156,7 @@ func fixMixedCharacters(config Config) error {
 
 	if !config.DryRun {
 		fmt.Println("\nCommitting changes to database...")
-		if err := commitChanges(config.DatabasePath); err != nil {
+		if err := writings.Commit("Fix mixed Arabic characters in transliterations"); err != nil {
 			return fmt.Errorf("failed to commit changes: %v", err)
 		}
 	}
@@ -153,46 +164,35 @@ func fixMixedCharacters(config Config) error {
 	return nil
 }
 
-func getTransliterationRecords(dbPath, langFilter string) ([]FixRecord, error) {
-	var whereClause string
+func getTransliterationRecords(writings store.WritingsStore, langFilter string) ([]FixRecord, error) {
+	var targetLangs []string
 	if langFilter == "both" {
-		whereClause = "WHERE language IN ('fa-translit', 'ar-translit')"
+		targetLangs = []string{"fa-translit", "ar-translit"}
 	} else if langFilter == "fa-translit" || langFilter == "ar-translit" {
-		whereClause = fmt.Sprintf("WHERE language = '%s'", langFilter)
+		targetLangs = []string{langFilter}
 	} else {
 		return nil, fmt.Errorf("invalid language filter: %s", langFilter)
 	}
 
-	query := fmt.Sprintf(`SELECT version, source_id, language, text FROM writings %s ORDER BY source_id`, whereClause)
-
-	cmd := exec.Command("dolt", "sql", "-q", query, "-r", "csv")
-	cmd.Dir = dbPath
-	
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute dolt sql: %v", err)
-	}
-
-	reader := csv.NewReader(strings.NewReader(string(output)))
-	rows, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse CSV output: %v", err)
-	}
-
 	var records []FixRecord
-	// Skip header row
-	for i := 1; i < len(rows); i++ {
-		if len(rows[i]) < 4 {
-			continue
+	for _, targetLang := range targetLangs {
+		// The transliteration rows come with the text they were made from
+		sourceLang := strings.TrimSuffix(targetLang, "-translit")
+		pairs, err := writings.ListPairs(sourceLang, targetLang)
+		if err != nil {
+			return nil, err
 		}
-		
-		record := FixRecord{
-			Version:      rows[i][0],
-			SourceID:     rows[i][1],
-			Language:     rows[i][2],
-			OriginalText: rows[i][3],
+
+		for _, pair := range pairs {
+			record := FixRecord{
+				Version:      pair.Version,
+				SourceID:     pair.SourceID,
+				Language:     targetLang,
+				SourceText:   pair.Text,
+				OriginalText: pair.Target,
+			}
+			records = append(records, record)
 		}
-		records = append(records, record)
 	}
 
 	return records, nil
@@ -306,80 +306,6 @@ func cleanMixedCharacters(text string) string {
 	return cleaned
 }
 
-func getOriginalText(dbPath, sourceID, translitLang string) (string, error) {
-	var originalLang string
-	if strings.HasPrefix(translitLang, "fa") {
-		originalLang = "fa"
-	} else {
-		originalLang = "ar"
-	}
-
-	query := fmt.Sprintf(`SELECT text FROM writings WHERE source_id = '%s' AND language = '%s' LIMIT 1`, sourceID, originalLang)
-
-	cmd := exec.Command("dolt", "sql", "-q", query, "-r", "csv")
-	cmd.Dir = dbPath
-	
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to execute dolt sql: %v", err)
-	}
-
-	reader := csv.NewReader(strings.NewReader(string(output)))
-	rows, err := reader.ReadAll()
-	if err != nil {
-		return "", fmt.Errorf("failed to parse CSV output: %v", err)
-	}
-
-	if len(rows) < 2 || len(rows[1]) < 1 {
-		return "", fmt.Errorf("no original text found for source_id %s", sourceID)
-	}
-
-	return rows[1][0], nil
-}
-
-func updateRecord(dbPath, version, newText string) error {
-	// Escape single quotes in the text for SQL
-	escapedText := strings.ReplaceAll(newText, "'", "''")
-	
-	query := fmt.Sprintf(`UPDATE writings SET text = '%s' WHERE version = '%s'`, escapedText, version)
-
-	cmd := exec.Command("dolt", "sql", "-q", query)
-	cmd.Dir = dbPath
-	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to update record: %v, output: %s", err, string(output))
-	}
-
-	return nil
-}
-
-func commitChanges(dbPath string) error {
-	commands := [][]string{
-		{"dolt", "add", "."},
-		{"dolt", "commit", "-m", "Fix mixed Arabic characters in transliterations"},
-		{"dolt", "push"},
-	}
-
-	for _, cmdArgs := range commands {
-		fmt.Printf("Executing: %s\n", strings.Join(cmdArgs, " "))
-		
-		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
-		cmd.Dir = dbPath
-		
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("failed to execute %s: %v, output: %s", strings.Join(cmdArgs, " "), err, string(output))
-		}
-		
-		if len(output) > 0 {
-			fmt.Printf("Output: %s\n", string(output))
-		}
-	}
-
-	return nil
-}
-
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
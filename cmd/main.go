@@ -15,10 +15,50 @@ func main() {
 		language = flag.String("lang", "auto", "Language: arabic, persian, or auto")
 		file     = flag.String("file", "", "Input file (if not provided, reads from stdin)")
 		verbose  = flag.Bool("verbose", false, "Verbose output")
+		irab     = flag.String("irab", "full", "Arabic case endings: full, pausal, or none")
+		quranic  = flag.String("quranic", "remove", "Quranic pause marks: remove or punctuation")
+		scheme   = flag.String("scheme", "bahai", "Romanization scheme: bahai, ala-lc, din, iso233, or ascii")
 	)
 	flag.Parse()
 
-	trans := transliterator.New()
+	var policy transliterator.IrabPolicy
+	switch strings.ToLower(*irab) {
+	case "full":
+		policy = transliterator.IrabFull
+	case "pausal":
+		policy = transliterator.IrabPausal
+	case "none":
+		policy = transliterator.IrabNone
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid i'rab policy: %s\n", *irab)
+		os.Exit(1)
+	}
+
+	var marks transliterator.QuranicMarkPolicy
+	switch strings.ToLower(*quranic) {
+	case "remove":
+		marks = transliterator.QuranicMarksRemove
+	case "punctuation":
+		marks = transliterator.QuranicMarksPunctuation
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid Quranic mark policy: %s\n", *quranic)
+		os.Exit(1)
+	}
+
+	romanization, err := transliterator.SchemeByName(*scheme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid scheme: %v\n", err)
+		os.Exit(1)
+	}
+
+	trans, err := transliterator.NewWithOptions(
+		transliterator.WithIrabPolicy(policy),
+		transliterator.WithQuranicMarks(marks),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing transliterator: %v\n", err)
+		os.Exit(1)
+	}
 
 	var input string
 	if *file != "" {
@@ -65,7 +105,7 @@ func main() {
 	}
 
 	// Transliterate
-	result := trans.Transliterate(input, lang)
+	result := trans.TransliterateScheme(input, lang, romanization)
 	
 	if *verbose {
 		fmt.Fprintf(os.Stderr, "Input length: %d characters\n", len(input))
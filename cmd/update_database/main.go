@@ -2,42 +2,73 @@ package main
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/LaPingvino/bahai-transliterator"
+	"github.com/LaPingvino/bahai-transliterator/store"
+	_ "github.com/go-sql-driver/mysql"
 )
 
 type Config struct {
 	DatabasePath string
+	DSN          string
 	DryRun       bool
 	BatchSize    int
 	Language     string
+	Force        bool
+	Gate         transliterator.QualityGate
+	NoGate       bool
+	ReviewPath   string
+	Checkpoint   string
+	Resume       bool
 }
 
 func main() {
 	var config Config
-	
+	config.Gate = transliterator.DefaultQualityGate()
+
 	flag.StringVar(&config.DatabasePath, "db", "", "Path to the bahaiwritings database directory")
+	flag.StringVar(&config.DSN, "dsn", "", "MySQL data source name of a running server such as dolt sql-server, instead of -db")
 	flag.BoolVar(&config.DryRun, "dry-run", false, "Show what would be updated without making changes")
-	flag.IntVar(&config.BatchSize, "batch-size", 10, "Number of records to process in each batch")
+	flag.IntVar(&config.BatchSize, "batch-size", 10, "Number of records written in each transaction")
 	flag.StringVar(&config.Language, "lang", "both", "Language to update: 'fa', 'ar', or 'both'")
+	flag.BoolVar(&config.Force, "force", false, "Overwrite every record that differs, not only verified improvements")
+	flag.BoolVar(&config.NoGate, "no-gate", false, "Write records without checking the quality of the new transliteration")
+	flag.Float64Var(&config.Gate.MinResolvedShare, "min-resolved", config.Gate.MinResolvedShare, "Minimum share of tokens resolved by the dictionary or by vowel marks")
+	flag.Float64Var(&config.Gate.MinConfidence, "min-confidence", config.Gate.MinConfidence, "Minimum mean confidence of the tokens")
+	flag.BoolVar(&config.Gate.AllowLeaked, "allow-leaked", false, "Accept Arabic-script characters left in the output")
+	flag.StringVar(&config.ReviewPath, "review", "update_review.csv", "CSV report of the records that were not written and why")
+	flag.StringVar(&config.Checkpoint, "checkpoint", "update_checkpoint.json", "File recording the last source_id written")
+	flag.BoolVar(&config.Resume, "resume", false, "Continue after the source_id recorded in the checkpoint file")
 	flag.Parse()
 
-	if config.DatabasePath == "" {
+	if config.DatabasePath == "" && config.DSN == "" {
 		fmt.Println("Usage: update_database -db /path/to/bahaiwritings")
 		fmt.Println("  -db string       Path to the bahaiwritings database directory")
+		fmt.Println("  -dsn string      MySQL data source name of a running server such as dolt sql-server, instead of -db")
 		fmt.Println("  -dry-run         Show what would be updated without making changes")
-		fmt.Println("  -batch-size int  Number of records to process in each batch (default 10)")
+		fmt.Println("  -batch-size int  Number of records written in each transaction (default 10)")
 		fmt.Println("  -lang string     Language to update: 'fa', 'ar', or 'both' (default 'both')")
+		fmt.Println("  -force           Overwrite every record that differs, not only verified improvements")
+		fmt.Println("  -no-gate         Write records without checking the quality of the new transliteration")
+		fmt.Println("  -min-resolved    Minimum share of tokens resolved by the dictionary or by vowel marks (default 0.25)")
+		fmt.Println("  -min-confidence  Minimum mean confidence of the tokens (default 0.5)")
+		fmt.Println("  -allow-leaked    Accept Arabic-script characters left in the output")
+		fmt.Println("  -review string   CSV report of the records that were not written and why (default 'update_review.csv')")
+		fmt.Println("  -checkpoint string  File recording the last source_id written (default 'update_checkpoint.json')")
+		fmt.Println("  -resume          Continue after the source_id recorded in the checkpoint file")
 		os.Exit(1)
 	}
 
+	if config.BatchSize < 1 {
+		config.BatchSize = 1
+	}
+
 	if err := updateDatabase(config); err != nil {
 		log.Fatalf("Error updating database: %v", err)
 	}
@@ -50,53 +81,126 @@ func updateDatabase(config Config) error {
 		return fmt.Errorf("failed to initialize transliterator: %v", err)
 	}
 
-	// Connect to database using dolt
-	dbPath := filepath.Join(config.DatabasePath, ".dolt")
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return fmt.Errorf("database path %s does not appear to be a dolt repository", config.DatabasePath)
+	// Commits are pushed to the remote
+	writings, err := store.Open(config.DatabasePath, config.DSN, true)
+	if err != nil {
+		return err
 	}
+	defer writings.Close()
 
-	// Use dolt sql commands for database operations
-	fmt.Printf("Updating transliterations in database: %s\n", config.DatabasePath)
+	return updateWritings(t, writings, config)
+}
+
+// updateWritings updates the transliterations of the languages in config, resuming
+// from the checkpoint if asked to, and commits the result
+func updateWritings(t *transliterator.Transliterator, writings store.WritingsStore, config Config) error {
+	if config.DSN != "" {
+		fmt.Println("Updating transliterations through the SQL server")
+	} else {
+		fmt.Printf("Updating transliterations in database: %s\n", config.DatabasePath)
+	}
 	fmt.Printf("Language filter: %s\n", config.Language)
 	fmt.Printf("Dry run: %t\n", config.DryRun)
 	fmt.Printf("Batch size: %d\n", config.BatchSize)
+	fmt.Printf("Quality gate: %t\n", !config.NoGate)
 
-	// Process Persian if requested
-	if config.Language == "fa" || config.Language == "both" {
-		if err := updateLanguage(t, config, "fa", "fa-translit"); err != nil {
-			return fmt.Errorf("failed to update Persian: %v", err)
+	var checkpoint *Checkpoint
+	if config.Resume {
+		var err error
+		checkpoint, err = loadCheckpoint(config.Checkpoint)
+		if err != nil {
+			return fmt.Errorf("failed to read checkpoint: %v", err)
+		}
+		if checkpoint == nil {
+			fmt.Printf("No checkpoint in %s, starting from the beginning\n", config.Checkpoint)
+		} else if config.Language != "both" && config.Language != checkpoint.Language {
+			return fmt.Errorf("checkpoint is for language %s, not %s", checkpoint.Language, config.Language)
 		}
 	}
 
-	// Process Arabic if requested
-	if config.Language == "ar" || config.Language == "both" {
-		if err := updateLanguage(t, config, "ar", "ar-translit"); err != nil {
-			return fmt.Errorf("failed to update Arabic: %v", err)
+	// A resumed run adds to the review report of the run it continues
+	if config.ReviewPath != "" && (checkpoint == nil || !fileExists(config.ReviewPath)) {
+		if err := createReviewReport(config.ReviewPath); err != nil {
+			return fmt.Errorf("failed to create review report: %v", err)
 		}
 	}
 
+	languages := []struct {
+		source, target, name string
+	}{
+		{"fa", "fa-translit", "Persian"},
+		{"ar", "ar-translit", "Arabic"},
+	}
+	reviewCount := 0
+	for _, l := range languages {
+		if config.Language != l.source && config.Language != "both" {
+			continue
+		}
+
+		// Languages before the one in the checkpoint were finished
+		resumeAfter := ""
+		if checkpoint != nil {
+			if checkpoint.Language != l.source {
+				fmt.Printf("\nSkipping %s, finished before the checkpoint\n", l.name)
+				continue
+			}
+			resumeAfter = checkpoint.SourceID
+			checkpoint = nil
+		}
+
+		count, err := updateLanguage(t, writings, config, l.source, l.target, resumeAfter)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %v", l.name, err)
+		}
+		reviewCount += count
+	}
+
+	if reviewCount > 0 && config.ReviewPath != "" {
+		fmt.Printf("\nWrote %d records to review to %s\n", reviewCount, config.ReviewPath)
+	}
+
 	if !config.DryRun {
 		fmt.Println("\nCommitting changes to database...")
-		if err := commitChanges(config.DatabasePath); err != nil {
+		if err := writings.Commit("Update transliterations with improved dictionary-based transliterator"); err != nil {
 			return fmt.Errorf("failed to commit changes: %v", err)
 		}
+
+		// The run is complete, there is nothing left to resume
+		if err := os.Remove(config.Checkpoint); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove checkpoint: %v", err)
+		}
 	}
 
 	return nil
 }
 
-func updateLanguage(t *transliterator.Transliterator, config Config, sourceLang, targetLang string) error {
+// updateLanguage writes the records of sourceLang after the source_id resumeAfter in
+// batches of one transaction each, and returns the number of records left for review
+func updateLanguage(t *transliterator.Transliterator, writings store.WritingsStore, config Config, sourceLang, targetLang, resumeAfter string) (int, error) {
 	fmt.Printf("\n=== Processing %s -> %s ===\n", sourceLang, targetLang)
 
 	// Get records to process
-	records, err := getRecordsToUpdate(config.DatabasePath, sourceLang, targetLang)
+	records, err := writings.ListPairs(sourceLang, targetLang)
 	if err != nil {
-		return fmt.Errorf("failed to get records: %v", err)
+		return 0, fmt.Errorf("failed to get records: %v", err)
 	}
 
 	fmt.Printf("Found %d records to process\n", len(records))
 
+	start := 0
+	if resumeAfter != "" {
+		start = -1
+		for i, record := range records {
+			if record.SourceID == resumeAfter {
+				start = i + 1
+			}
+		}
+		if start < 0 {
+			return 0, fmt.Errorf("checkpoint source_id %s not found", resumeAfter)
+		}
+		fmt.Printf("Resuming after source_id %s, skipping %d records\n", resumeAfter, start)
+	}
+
 	var lang transliterator.Language
 	if sourceLang == "fa" {
 		lang = transliterator.Persian
@@ -106,131 +210,178 @@ func updateLanguage(t *transliterator.Transliterator, config Config, sourceLang,
 
 	updatedCount := 0
 	unchangedCount := 0
-
-	for i, record := range records {
-		if i%config.BatchSize == 0 {
-			fmt.Printf("Processing batch %d-%d...\n", i+1, min(i+config.BatchSize, len(records)))
+	reviewCount := 0
+
+	for begin := start; begin < len(records); {
+		// Rows of one source_id stay in the same batch, so the checkpoint can name
+		// the last one written
+		end := min(begin+config.BatchSize, len(records))
+		for end < len(records) && records[end].SourceID == records[end-1].SourceID {
+			end++
 		}
+		fmt.Printf("Processing batch %d-%d...\n", begin+1, end)
+
+		var updates []store.Update
+		var review []ReviewEntry
+		for _, record := range records[begin:end] {
+			// Transliterate the text
+			result, err := t.TransliterateWithReport(record.Text, lang)
+			if err != nil {
+				review = append(review, ReviewEntry{record, sourceLang, "", []string{err.Error()}})
+				continue
+			}
+			newTranslit := result.Text
+			if newTranslit == record.Target {
+				unchangedCount++
+				continue
+			}
 
-		// Transliterate the text
-		newTranslit := t.Transliterate(record.Text, lang)
+			// Overwrite only when the new output passes the quality gate and agrees with
+			// the current one on the consonants of every word, or differs from it in
+			// spacing and punctuation
+			var reasons []string
+			if !config.NoGate {
+				reasons = config.Gate.Check(result.Quality())
+			}
+			comparison := transliterator.CompareRomanizations(record.Target, newTranslit)
+			if !comparison.Identical() && !comparison.Improvement() && !config.Force {
+				reasons = append(reasons, fmt.Sprintf("differs from the current transliteration in %d consonant, %d missing and %d extra words",
+					comparison.Count(transliterator.DiffConsonant),
+					comparison.Count(transliterator.DiffMissing),
+					comparison.Count(transliterator.DiffExtra)))
+			}
+
+			if len(reasons) > 0 {
+				fmt.Printf("  Needs review %s (source_id: %s): %s\n", record.Name, record.SourceID, strings.Join(reasons, "; "))
+				review = append(review, ReviewEntry{record, sourceLang, newTranslit, reasons})
+				continue
+			}
 
-		// Check if it's different from current
-		if newTranslit != record.CurrentTranslit {
 			fmt.Printf("  Updating %s (source_id: %s)\n", record.Name, record.SourceID)
-			if !config.DryRun {
-				if err := updateRecord(config.DatabasePath, record.Version, newTranslit); err != nil {
-					return fmt.Errorf("failed to update record %s: %v", record.Version, err)
-				}
+			updates = append(updates, store.Update{Version: record.Version, Text: newTranslit})
+		}
+
+		// The batch is reported once it is written, so a resumed run does not report
+		// its records twice
+		if !config.DryRun {
+			if err := writings.UpdateTexts(updates); err != nil {
+				return 0, fmt.Errorf("failed to update batch %d-%d: %v", begin+1, end, err)
+			}
+		}
+		if config.ReviewPath != "" {
+			if err := appendReviewReport(config.ReviewPath, review); err != nil {
+				return 0, fmt.Errorf("failed to write review report: %v", err)
+			}
+		}
+		reviewCount += len(review)
+
+		if !config.DryRun {
+			if err := saveCheckpoint(config.Checkpoint, Checkpoint{sourceLang, records[end-1].SourceID}); err != nil {
+				return 0, fmt.Errorf("failed to save checkpoint: %v", err)
 			}
-			updatedCount++
-		} else {
-			unchangedCount++
 		}
+		updatedCount += len(updates)
+		begin = end
 	}
 
 	fmt.Printf("\nSummary for %s:\n", sourceLang)
 	fmt.Printf("  Updated: %d records\n", updatedCount)
 	fmt.Printf("  Unchanged: %d records\n", unchangedCount)
-	fmt.Printf("  Total: %d records\n", len(records))
+	fmt.Printf("  Needs review: %d records\n", reviewCount)
+	fmt.Printf("  Total: %d records\n", len(records)-start)
 
-	return nil
+	return reviewCount, nil
 }
 
-type Record struct {
-	Version         string
-	SourceID        string
-	Name            string
-	Text            string
-	CurrentTranslit string
+// Checkpoint records how far a run got, so that -resume can continue it
+type Checkpoint struct {
+	Language string `json:"language"`  // source language being processed
+	SourceID string `json:"source_id"` // last source_id whose batch was written
 }
 
-func getRecordsToUpdate(dbPath, sourceLang, targetLang string) ([]Record, error) {
-	// Use dolt sql to get records
-	query := fmt.Sprintf(`SELECT w2.version, w1.source_id, COALESCE(w1.name, '') as name, w1.text, w2.text as current_translit FROM writings w1 JOIN writings w2 ON w1.source = w2.source AND w1.source_id = w2.source_id WHERE w1.language = '%s' AND w2.language = '%s' ORDER BY w1.source_id`, sourceLang, targetLang)
-
-	cmd := exec.Command("dolt", "sql", "-q", query, "-r", "csv")
-	cmd.Dir = dbPath
-	
-	output, err := cmd.Output()
+// loadCheckpoint reads the checkpoint at path, or returns nil if there is none
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute dolt sql: %v", err)
+		return nil, err
 	}
 
-	// Parse CSV output
-	reader := csv.NewReader(strings.NewReader(string(output)))
-	rows, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse CSV output: %v", err)
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %v", path, err)
 	}
+	return &checkpoint, nil
+}
 
-	var records []Record
-	// Skip header row
-	for i := 1; i < len(rows); i++ {
-		if len(rows[i]) < 5 {
-			continue // Skip malformed rows
-		}
-		
-		record := Record{
-			Version:         rows[i][0],
-			SourceID:        rows[i][1],
-			Name:            rows[i][2],
-			Text:            rows[i][3],
-			CurrentTranslit: rows[i][4],
-		}
-		records = append(records, record)
+// saveCheckpoint writes the checkpoint to a temporary file and renames it over path,
+// so a crash never leaves half a checkpoint
+func saveCheckpoint(path string, checkpoint Checkpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
 	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
 
-	return records, nil
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
-func updateRecord(dbPath, version, newTranslit string) error {
-	// Escape single quotes in the text for SQL
-	escapedText := strings.ReplaceAll(newTranslit, "'", "''")
-	
-	query := fmt.Sprintf(`UPDATE writings SET text = '%s' WHERE version = '%s'`, escapedText, version)
+// ReviewEntry is a record that was not written, with the reasons why
+type ReviewEntry struct {
+	Record      store.Pair
+	Language    string
+	NewTranslit string
+	Reasons     []string
+}
 
-	cmd := exec.Command("dolt", "sql", "-q", query)
-	cmd.Dir = dbPath
-	
-	output, err := cmd.CombinedOutput()
+func createReviewReport(path string) error {
+	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("failed to update record: %v, output: %s", err, string(output))
+		return err
 	}
+	defer file.Close()
 
-	return nil
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"version", "source_id", "name", "language", "reasons", "current_translit", "new_translit"})
+	writer.Flush()
+
+	return writer.Error()
 }
 
-func commitChanges(dbPath string) error {
-	// Use dolt commands to add, commit, and push changes
-	commands := [][]string{
-		{"dolt", "add", "."},
-		{"dolt", "commit", "-m", "Update transliterations with improved dictionary-based transliterator"},
-		{"dolt", "push"},
-	}
-
-	for _, cmdArgs := range commands {
-		fmt.Printf("Executing: %s\n", strings.Join(cmdArgs, " "))
-		
-		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
-		cmd.Dir = dbPath
-		
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("failed to execute %s: %v, output: %s", strings.Join(cmdArgs, " "), err, string(output))
-		}
-		
-		if len(output) > 0 {
-			fmt.Printf("Output: %s\n", string(output))
-		}
+// appendReviewReport adds entries to the report, batch by batch, so a crash keeps the
+// entries of the batches before it
+func appendReviewReport(path string, entries []ReviewEntry) error {
+	if len(entries) == 0 {
+		return nil
 	}
 
-	return nil
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
 	}
-	return b
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	for _, entry := range entries {
+		writer.Write([]string{
+			entry.Record.Version,
+			entry.Record.SourceID,
+			entry.Record.Name,
+			entry.Language,
+			strings.Join(entry.Reasons, "; "),
+			entry.Record.Target,
+			entry.NewTranslit,
+		})
+	}
+	writer.Flush()
+
+	return writer.Error()
 }
\ No newline at end of file
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LaPingvino/bahai-transliterator"
+	"github.com/LaPingvino/bahai-transliterator/store"
+)
+
+// recordingStore records the batches written and fails the batch numbered failAt
+type recordingStore struct {
+	*store.MemoryStore
+	failAt  int
+	batches [][]string // versions of each batch, failed ones included
+}
+
+func (s *recordingStore) UpdateTexts(updates []store.Update) error {
+	var versions []string
+	for _, u := range updates {
+		versions = append(versions, u.Version)
+	}
+	s.batches = append(s.batches, versions)
+	if len(s.batches) == s.failAt {
+		return errors.New("connection lost")
+	}
+	return s.MemoryStore.UpdateTexts(updates)
+}
+
+// testStore has a Persian and an Arabic writing for every source_id, each with as
+// many transliteration rows as its count
+func testStore(counts map[string]int) *recordingStore {
+	var writings []store.Writing
+	for _, l := range []struct{ lang, text string }{{"fa", "هو الله"}, {"ar", "يا إلهي"}} {
+		for id, n := range counts {
+			writings = append(writings, store.Writing{Version: l.lang + id, Source: "prayers", SourceID: id, Language: l.lang, Text: l.text})
+			for i := 0; i < n; i++ {
+				version := l.lang + id + "t" + string(rune('1'+i))
+				writings = append(writings, store.Writing{Version: version, Source: "prayers", SourceID: id, Language: l.lang + "-translit", Text: "old"})
+			}
+		}
+	}
+	return &recordingStore{MemoryStore: &store.MemoryStore{Writings: writings}}
+}
+
+func testConfig(t *testing.T) Config {
+	return Config{
+		DSN:        "test",
+		BatchSize:  2,
+		Language:   "both",
+		Force:      true,
+		NoGate:     true,
+		Checkpoint: filepath.Join(t.TempDir(), "checkpoint.json"),
+	}
+}
+
+func newTransliterator(t *testing.T) *transliterator.Transliterator {
+	trans, err := transliterator.New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	return trans
+}
+
+func TestUpdateLanguageBatches(t *testing.T) {
+	trans := newTransliterator(t)
+	config := testConfig(t)
+	writings := testStore(map[string]int{"1": 3, "2": 1, "3": 1})
+
+	if _, err := updateLanguage(trans, writings, config, "fa", "fa-translit", ""); err != nil {
+		t.Fatalf("updateLanguage failed: %v", err)
+	}
+
+	// The three rows of source_id 1 stay together in a batch larger than the batch size
+	expected := [][]string{{"fa1t1", "fa1t2", "fa1t3"}, {"fa2t1", "fa3t1"}}
+	if !reflect.DeepEqual(writings.batches, expected) {
+		t.Errorf("batches = %q, expected %q", writings.batches, expected)
+	}
+	if checkpoint, err := loadCheckpoint(config.Checkpoint); err != nil || *checkpoint != (Checkpoint{"fa", "3"}) {
+		t.Errorf("checkpoint = %+v (%v), expected fa after source_id 3", checkpoint, err)
+	}
+}
+
+func TestUpdateLanguageResume(t *testing.T) {
+	trans := newTransliterator(t)
+	config := testConfig(t)
+	writings := testStore(map[string]int{"1": 2, "2": 1, "3": 1})
+
+	if _, err := updateLanguage(trans, writings, config, "fa", "fa-translit", "1"); err != nil {
+		t.Fatalf("updateLanguage failed: %v", err)
+	}
+	expected := [][]string{{"fa2t1", "fa3t1"}}
+	if !reflect.DeepEqual(writings.batches, expected) {
+		t.Errorf("batches = %q, expected %q", writings.batches, expected)
+	}
+
+	_, err := updateLanguage(trans, writings, config, "fa", "fa-translit", "9")
+	if err == nil || !strings.Contains(err.Error(), "source_id 9 not found") {
+		t.Errorf("resuming after a missing source_id: err = %v", err)
+	}
+}
+
+func TestUpdateLanguageFailedBatch(t *testing.T) {
+	trans := newTransliterator(t)
+	config := testConfig(t)
+	writings := testStore(map[string]int{"1": 2, "2": 2, "3": 1})
+	writings.failAt = 2
+
+	if _, err := updateLanguage(trans, writings, config, "ar", "ar-translit", ""); err == nil {
+		t.Fatalf("updateLanguage should fail with the second batch")
+	}
+
+	// The checkpoint still names the last batch written, whose rows were updated, and
+	// the failed batch changed nothing
+	if checkpoint, err := loadCheckpoint(config.Checkpoint); err != nil || *checkpoint != (Checkpoint{"ar", "1"}) {
+		t.Errorf("checkpoint = %+v (%v), expected ar after source_id 1", checkpoint, err)
+	}
+	for _, w := range writings.Writings {
+		updated := w.Text != "old"
+		if w.Language == "ar-translit" && updated != (w.SourceID == "1") {
+			t.Errorf("row %s has text %q after the failed batch", w.Version, w.Text)
+		}
+	}
+}
+
+func TestUpdateWritingsResumeSecondLanguage(t *testing.T) {
+	trans := newTransliterator(t)
+	config := testConfig(t)
+	config.Resume = true
+	writings := testStore(map[string]int{"1": 1, "2": 1, "3": 1})
+
+	if err := saveCheckpoint(config.Checkpoint, Checkpoint{"ar", "2"}); err != nil {
+		t.Fatalf("saveCheckpoint failed: %v", err)
+	}
+	if err := updateWritings(trans, writings, config); err != nil {
+		t.Fatalf("updateWritings failed: %v", err)
+	}
+
+	// Persian was finished before the checkpoint and Arabic continues after it
+	expected := [][]string{{"ar3t1"}}
+	if !reflect.DeepEqual(writings.batches, expected) {
+		t.Errorf("batches = %q, expected %q", writings.batches, expected)
+	}
+	if len(writings.Commits) != 1 {
+		t.Errorf("Commits = %q, expected one commit", writings.Commits)
+	}
+	if checkpoint, err := loadCheckpoint(config.Checkpoint); err != nil || checkpoint != nil {
+		t.Errorf("checkpoint = %+v (%v), expected it removed after a complete run", checkpoint, err)
+	}
+}
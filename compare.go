@@ -0,0 +1,243 @@
+package transliterator
+
+import "strings"
+
+// DifferenceKind classifies how a word of a legacy romanization differs from fresh
+// output
+type DifferenceKind string
+
+const (
+	DiffSame      DifferenceKind = "same"      // the words are identical
+	DiffDiacritic DifferenceKind = "diacritic" // only accents, apostrophes, case, hyphens or punctuation differ
+	DiffVowel     DifferenceKind = "vowel"     // the consonants agree, the vowels differ
+	DiffConsonant DifferenceKind = "consonant" // the consonants differ
+	DiffMissing   DifferenceKind = "missing"   // the output has a word the legacy romanization lacks
+	DiffExtra     DifferenceKind = "extra"     // the legacy romanization has a word the output lacks
+)
+
+// WordDifference pairs words of a legacy romanization with words of fresh output.
+// Legacy and Output hold one word each, or two when one side writes as two words what
+// the other joins (fí aldunya and fi'd-dunyá).
+type WordDifference struct {
+	Legacy string // empty for DiffMissing
+	Output string // empty for DiffExtra
+	Kind   DifferenceKind
+}
+
+// Comparison is the word by word alignment of a legacy romanization with fresh output
+type Comparison struct {
+	Words []WordDifference
+}
+
+// Count returns the number of aligned words of the given kind
+func (c Comparison) Count(kind DifferenceKind) int {
+	count := 0
+	for _, w := range c.Words {
+		if w.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Identical tells whether the two romanizations agree word for word
+func (c Comparison) Identical() bool {
+	return c.Count(DiffSame) == len(c.Words)
+}
+
+// Improvement tells whether the output may safely replace the legacy romanization:
+// it fills an empty one, or it differs from it in vowels and diacritics only, so both
+// read the same consonants of the same words
+func (c Comparison) Improvement() bool {
+	if len(c.Words) > 0 && c.Count(DiffMissing) == len(c.Words) {
+		return true
+	}
+	if c.Identical() {
+		return false
+	}
+	return c.Count(DiffConsonant)+c.Count(DiffMissing)+c.Count(DiffExtra) == 0
+}
+
+// compareWord is a word of a romanization with the forms it is compared by
+type compareWord struct {
+	text     string
+	key      string // SearchKey, without spaces
+	skeleton string // the consonants of key
+}
+
+func newCompareWord(text string) compareWord {
+	key := strings.ReplaceAll(SearchKey(text), " ", "")
+	return compareWord{text: text, key: key, skeleton: consonantSkeleton(key)}
+}
+
+// joinWords compares two words written as one
+func joinWords(a, b compareWord) compareWord {
+	w := newCompareWord(a.text + b.text)
+	w.text = a.text + " " + b.text
+	return w
+}
+
+// consonantSkeleton drops the vowels of a search key. W and v count as one consonant,
+// as both write و, and doubled consonants as one, as legacy romanizations of unvocalized
+// text miss the shadda.
+func consonantSkeleton(key string) string {
+	var result []byte
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if strings.IndexByte("aeiou", c) >= 0 {
+			continue
+		}
+		if c == 'v' {
+			c = 'w'
+		}
+		if len(result) > 0 && result[len(result)-1] == c {
+			continue
+		}
+		result = append(result, c)
+	}
+	return string(result)
+}
+
+// classifyWords tells how the legacy word differs from the output word
+func classifyWords(legacy, output compareWord) DifferenceKind {
+	switch {
+	case legacy.text == output.text:
+		return DiffSame
+	case legacy.key == output.key:
+		return DiffDiacritic
+	case legacy.skeleton == output.skeleton:
+		return DiffVowel
+	}
+	return DiffConsonant
+}
+
+// Costs of the alignment steps. A pair of words that share no consonants costs as
+// much as a missing and an extra word, so unrelated words are not paired.
+const (
+	costGap   = 1.0  // a missing or extra word
+	costVowel = 0.25 // a pair that differs in vowels
+	costJoin  = 0.25 // two words compared as one
+)
+
+// pairCost is the cost of aligning legacy with output
+func pairCost(legacy, output compareWord) (float64, DifferenceKind) {
+	kind := classifyWords(legacy, output)
+	switch kind {
+	case DiffSame, DiffDiacritic:
+		return 0, kind
+	case DiffVowel:
+		return costVowel, kind
+	}
+	distance := float64(editDistance(legacy.skeleton, output.skeleton))
+	length := float64(max(len(legacy.skeleton), len(output.skeleton)))
+	return costVowel + (2*costGap-costVowel)*distance/length, kind
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// CompareRomanizations aligns a legacy romanization with fresh output word by word and
+// classifies every difference. Words that are only punctuation are ignored.
+func CompareRomanizations(legacy, output string) Comparison {
+	words := func(text string) []compareWord {
+		var result []compareWord
+		for _, field := range strings.Fields(text) {
+			if w := newCompareWord(field); w.key != "" {
+				result = append(result, w)
+			}
+		}
+		return result
+	}
+	l, o := words(legacy), words(output)
+
+	// cost[i][j] aligns the first i legacy words with the first j output words; step
+	// records how many words of each side the last step took
+	type step struct {
+		legacy, output int
+		kind           DifferenceKind
+	}
+	cost := make([][]float64, len(l)+1)
+	steps := make([][]step, len(l)+1)
+	for i := range cost {
+		cost[i] = make([]float64, len(o)+1)
+		steps[i] = make([]step, len(o)+1)
+	}
+
+	for i := 0; i <= len(l); i++ {
+		for j := 0; j <= len(o); j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			best := -1.0
+			try := func(c float64, s step) {
+				c += cost[i-s.legacy][j-s.output]
+				if best < 0 || c < best {
+					best = c
+					steps[i][j] = s
+				}
+			}
+			if i > 0 && j > 0 {
+				c, kind := pairCost(l[i-1], o[j-1])
+				try(c, step{1, 1, kind})
+			}
+			if i > 1 && j > 0 {
+				c, kind := pairCost(joinWords(l[i-2], l[i-1]), o[j-1])
+				try(c+costJoin, step{2, 1, kind})
+			}
+			if i > 0 && j > 1 {
+				c, kind := pairCost(l[i-1], joinWords(o[j-2], o[j-1]))
+				try(c+costJoin, step{1, 2, kind})
+			}
+			if i > 0 {
+				try(costGap, step{1, 0, DiffExtra})
+			}
+			if j > 0 {
+				try(costGap, step{0, 1, DiffMissing})
+			}
+			cost[i][j] = best
+		}
+	}
+
+	var result []WordDifference
+	for i, j := len(l), len(o); i > 0 || j > 0; {
+		s := steps[i][j]
+		d := WordDifference{Kind: s.kind}
+		switch s.legacy {
+		case 1:
+			d.Legacy = l[i-1].text
+		case 2:
+			d.Legacy = l[i-2].text + " " + l[i-1].text
+		}
+		switch s.output {
+		case 1:
+			d.Output = o[j-1].text
+		case 2:
+			d.Output = o[j-2].text + " " + o[j-1].text
+		}
+		result = append(result, d)
+		i, j = i-s.legacy, j-s.output
+	}
+	for a, b := 0, len(result)-1; a < b; a, b = a+1, b-1 {
+		result[a], result[b] = result[b], result[a]
+	}
+
+	return Comparison{Words: result}
+}
@@ -0,0 +1,111 @@
+package transliterator
+
+import "testing"
+
+func TestCompareRomanizations(t *testing.T) {
+	tests := []struct {
+		name     string
+		legacy   string
+		output   string
+		expected []WordDifference
+	}{
+		{
+			"persian legacy row",
+			"Húvállh ay yzdáni mhrbán",
+			"Huva'lláh! Ay Yazdán-i mihrabán",
+			[]WordDifference{
+				{"Húvállh", "Huva'lláh!", DiffVowel},
+				{"ay", "Ay", DiffDiacritic},
+				{"yzdáni", "Yazdán-i", DiffVowel},
+				{"mhrbán", "mihrabán", DiffVowel},
+			},
+		},
+		{
+			"w and v, missing shadda",
+			"vahubuka shifaií",
+			"wa-ḥubbuka shifá'í",
+			[]WordDifference{
+				{"vahubuka", "wa-ḥubbuka", DiffVowel},
+				{"shifaií", "shifá'í", DiffVowel},
+			},
+		},
+		{
+			"consonants differ",
+			"múnisí tabíbí",
+			"mawanasay ṭabíbí",
+			[]WordDifference{
+				{"múnisí", "mawanasay", DiffConsonant},
+				{"tabíbí", "ṭabíbí", DiffDiacritic},
+			},
+		},
+		{
+			"words joined and split",
+			"fí aldunya vaiinaka",
+			"fí'd-dunyá wa-innaka",
+			[]WordDifference{
+				{"fí aldunya", "fí'd-dunyá", DiffConsonant},
+				{"vaiinaka", "wa-innaka", DiffVowel},
+			},
+		},
+		{
+			"missing and extra words",
+			"# Bismihi almuhaymini alá alásmai",
+			"Bismihi'l-Muhaymin",
+			[]WordDifference{
+				{"Bismihi almuhaymini", "Bismihi'l-Muhaymin", DiffVowel},
+				{"alá", "", DiffExtra},
+				{"alásmai", "", DiffExtra},
+			},
+		},
+		{
+			"word missing from the legacy",
+			"Ya Iláhí",
+			"yá Iláhí, ismuka",
+			[]WordDifference{
+				{"Ya", "yá", DiffDiacritic},
+				{"Iláhí", "Iláhí,", DiffDiacritic},
+				{"", "ismuka", DiffMissing},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CompareRomanizations(tt.legacy, tt.output)
+			if len(result.Words) != len(tt.expected) {
+				t.Fatalf("CompareRomanizations(%q, %q) = %+v, expected %+v", tt.legacy, tt.output, result.Words, tt.expected)
+			}
+			for i, w := range result.Words {
+				if w != tt.expected[i] {
+					t.Errorf("word %d = %+v, expected %+v", i, w, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestComparisonImprovement(t *testing.T) {
+	tests := []struct {
+		legacy      string
+		output      string
+		identical   bool
+		improvement bool
+	}{
+		{"Húvállh ay yzdáni mhrbán", "Huva'lláh! Ay Yazdán-i mihrabán", false, true},
+		{"Iláhí ismuka", "Iláhí ismuka", true, false},
+		{"múnisí tabíbí", "mawanasay ṭabíbí", false, false},
+		{"Ya Iláhí", "yá Iláhí, ismuka", false, false},
+		{"", "yá Iláhí", false, true},
+		{"", "", true, false},
+	}
+
+	for _, tt := range tests {
+		result := CompareRomanizations(tt.legacy, tt.output)
+		if result.Identical() != tt.identical {
+			t.Errorf("CompareRomanizations(%q, %q).Identical() = %v", tt.legacy, tt.output, result.Identical())
+		}
+		if result.Improvement() != tt.improvement {
+			t.Errorf("CompareRomanizations(%q, %q).Improvement() = %v", tt.legacy, tt.output, result.Improvement())
+		}
+	}
+}
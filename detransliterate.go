@@ -0,0 +1,654 @@
+package transliterator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Candidate is an Arabic-script spelling proposed for romanized text
+type Candidate struct {
+	Text       string  // the spelling, without vowel marks
+	Score      float64 // between 0 and 1, higher is more likely
+	Dictionary bool    // the spelling comes from dictionary entries
+}
+
+// Detransliteration lists the candidate spellings of one romanized word, or of a
+// romanized dictionary phrase
+type Detransliteration struct {
+	Roman      string      // the romanized text, as written in the input
+	Candidates []Candidate // best first
+}
+
+// maxCandidates is the number of candidates kept for every word
+const maxCandidates = 5
+
+// romanPunctuation gives the Arabic-script form of the punctuation of romanized text
+var romanPunctuation = map[rune]string{
+	',': "،", ';': "؛", '?': "؟", '.': ".", ':': ":", '!': "!",
+}
+
+// reverseIndex finds dictionary entries by their transliteration
+type reverseIndex struct {
+	exact    map[string][]string // canonical transliteration → spellings
+	loose    map[string][]string // search key → spellings
+	maxWords int                 // number of words of the longest phrase
+}
+
+// buildReverseIndex indexes the common words, divine names and common phrases of a
+// dictionary by their transliteration
+func buildReverseIndex(dict *Dictionary) *reverseIndex {
+	idx := &reverseIndex{
+		exact:    make(map[string][]string),
+		loose:    make(map[string][]string),
+		maxWords: 1,
+	}
+
+	add := func(spelling, trans string) {
+		if spelling == "" || trans == "" {
+			return
+		}
+		core, _ := splitRomanPunctuation(trans)
+		idx.exact[canonicalRoman(core)] = append(idx.exact[canonicalRoman(core)], spelling)
+		idx.loose[SearchKey(core)] = append(idx.loose[SearchKey(core)], spelling)
+		if n := len(strings.Fields(core)); n > idx.maxWords {
+			idx.maxWords = n
+		}
+	}
+	for spelling, entry := range dict.CommonWords {
+		add(spelling, entry.Transliteration)
+	}
+	for spelling, entry := range dict.DivineNames {
+		add(spelling, entry.Transliteration)
+	}
+	for spelling, entry := range dict.CommonPhrases {
+		add(spelling, entry.Transliteration)
+	}
+
+	for _, spellings := range []map[string][]string{idx.exact, idx.loose} {
+		for key, list := range spellings {
+			spellings[key] = uniqueSorted(list)
+		}
+	}
+
+	return idx
+}
+
+// canonicalRoman lower-cases a romanization and writes its long vowels and
+// apostrophes as in the Bahá'í scheme (ā → á, ʿ → ')
+func canonicalRoman(roman string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(searchApostrophes, r):
+			return '\''
+		case r == 'ā':
+			return 'á'
+		case r == 'ī':
+			return 'í'
+		case r == 'ū':
+			return 'ú'
+		}
+		return r
+	}, strings.ToLower(roman))
+}
+
+// splitRomanPunctuation removes the sentence punctuation from the end of a
+// romanized word. Apostrophes are letters here (shifá').
+func splitRomanPunctuation(word string) (string, string) {
+	core := strings.TrimRight(word, ",.;:!?\"()«»")
+	return core, word[len(core):]
+}
+
+// arabicPunctuation writes romanized punctuation in Arabic script
+func arabicPunctuation(punct string) string {
+	var result strings.Builder
+	for _, r := range punct {
+		if arabic, ok := romanPunctuation[r]; ok {
+			result.WriteString(arabic)
+		}
+	}
+	return result.String()
+}
+
+// Detransliterate reads Bahá'í-style romanized text back into Arabic script. Every
+// word, or dictionary phrase, gets ranked candidate spellings: dictionary entries
+// whose transliteration matches first, then spellings built letter by letter, where
+// ' may be ʿayn or hamza, a final -ih or -ah a ta marbuta, and so on. Long vowels may
+// be written with acutes or macrons, and ʿayn and hamza with any apostrophe.
+func (t *Transliterator) Detransliterate(text string, lang Language) ([]Detransliteration, error) {
+	if lang != Arabic && lang != Persian {
+		return nil, fmt.Errorf("unknown language %d", lang)
+	}
+
+	idx := t.reverse[lang]
+	words := strings.Fields(text)
+	var result []Detransliteration
+
+	for i := 0; i < len(words); {
+		// Dictionary phrases, longest first
+		matched := false
+		for n := min(idx.maxWords, len(words)-i); n > 1; n-- {
+			roman := strings.Join(words[i:i+n], " ")
+			core, punct := splitRomanPunctuation(roman)
+			if spellings := idx.exact[canonicalRoman(core)]; len(spellings) > 0 {
+				result = append(result, Detransliteration{
+					Roman:      roman,
+					Candidates: dictionaryCandidates(spellings, 1, arabicPunctuation(punct)),
+				})
+				i += n
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		result = append(result, Detransliteration{
+			Roman:      words[i],
+			Candidates: t.detransliterateWord(words[i], lang),
+		})
+		i++
+	}
+
+	return result, nil
+}
+
+// dictionaryCandidates turns dictionary spellings into candidates of the given score,
+// slightly lower for each further spelling so the order is stable
+func dictionaryCandidates(spellings []string, score float64, punct string) []Candidate {
+	var candidates []Candidate
+	for i, spelling := range spellings {
+		candidates = append(candidates, Candidate{
+			Text:       spelling + punct,
+			Score:      score - 0.01*float64(i),
+			Dictionary: true,
+		})
+	}
+	return candidates
+}
+
+// detransliterateWord proposes spellings for one romanized word
+func (t *Transliterator) detransliterateWord(word string, lang Language) []Candidate {
+	// Punctuation and numbers have no spelling to propose
+	if strings.IndexFunc(word, unicode.IsLetter) < 0 {
+		return []Candidate{{Text: word, Score: 1}}
+	}
+
+	idx := t.reverse[lang]
+	core, punct := splitRomanPunctuation(word)
+	punct = arabicPunctuation(punct)
+
+	best := make(map[string]Candidate)
+	add := func(c Candidate) {
+		if existing, ok := best[c.Text]; !ok || c.Score > existing.Score {
+			best[c.Text] = c
+		}
+	}
+
+	for _, c := range dictionaryCandidates(idx.exact[canonicalRoman(core)], 1, punct) {
+		add(c)
+	}
+	for _, c := range dictionaryCandidates(idx.loose[SearchKey(core)], 0.8, punct) {
+		add(c)
+	}
+	for _, s := range t.reverseParts(strings.Split(canonicalRoman(core), "-"), lang) {
+		score := s.score * 0.5
+		if s.dictionary {
+			score = s.score * 0.9
+		}
+		add(Candidate{Text: s.text + punct, Score: score, Dictionary: s.dictionary})
+	}
+
+	candidates := make([]Candidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Text < candidates[j].Text
+	})
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+	return candidates
+}
+
+// spelling is a partial Arabic-script spelling and its likelihood
+type spelling struct {
+	text       string
+	score      float64
+	dictionary bool // every part came from the dictionary
+}
+
+// beamWidth is the number of partial spellings kept while reading a word
+const beamWidth = 16
+
+// pruneSpellings keeps the most likely spellings, merging duplicates
+func pruneSpellings(spellings []spelling) []spelling {
+	best := make(map[string]spelling)
+	for _, s := range spellings {
+		if existing, ok := best[s.text]; !ok || s.score > existing.score {
+			best[s.text] = s
+		}
+	}
+	pruned := make([]spelling, 0, len(best))
+	for _, s := range best {
+		pruned = append(pruned, s)
+	}
+	sort.Slice(pruned, func(i, j int) bool {
+		if pruned[i].score != pruned[j].score {
+			return pruned[i].score > pruned[j].score
+		}
+		return pruned[i].text < pruned[j].text
+	})
+	if len(pruned) > beamWidth {
+		pruned = pruned[:beamWidth]
+	}
+	return pruned
+}
+
+// extendSpellings appends every option to every spelling, separated by sep
+func extendSpellings(spellings, options []spelling, sep string) []spelling {
+	var extended []spelling
+	for _, s := range spellings {
+		for _, o := range options {
+			text := o.text
+			if s.text != "" && o.text != "" {
+				text = s.text + sep + o.text
+			} else if s.text != "" {
+				text = s.text
+			}
+			extended = append(extended, spelling{
+				text:       text,
+				score:      s.score * o.score,
+				dictionary: s.dictionary && o.dictionary,
+			})
+		}
+	}
+	return pruneSpellings(extended)
+}
+
+// reverseParts spells the hyphen-separated parts of a romanized word: proclitics
+// (wa-, bi-) and the article (al-, ash-, 'l-) are attached to the word they precede,
+// the Persian ezafe (-i, -yi) is dropped or written ی, and other parts are separate
+// words
+func (t *Transliterator) reverseParts(parts []string, lang Language) []spelling {
+	article := t.articles[lang]
+	if article == nil {
+		article = t.articles[Arabic]
+	}
+	letters := t.letterMap(Arabic)
+
+	// sunArticle reports whether part ends with the romanized sun letter next starts
+	// with, as the assimilated article does (ash-shams, fí'd-dunyá)
+	sunArticle := func(part, next string) (string, bool) {
+		if article == nil {
+			return "", false
+		}
+		for _, r := range article.SunLetters {
+			sun := letters[r]
+			if sun != "" && strings.HasSuffix(part, sun) && strings.HasPrefix(next, sun) {
+				return strings.TrimSuffix(part, sun), true
+			}
+		}
+		return "", false
+	}
+
+	beam := []spelling{{score: 1, dictionary: true}}
+	sep := " "
+	articlePending := false
+	spelled := false // the beam only says where a spelling came from once a word is in it
+
+	for j, part := range parts {
+		last := j == len(parts)-1
+		next := ""
+		if !last {
+			next = parts[j+1]
+		}
+
+		switch {
+		case part == "":
+			continue
+		case lang == Persian && j > 0 && (part == "i" || part == "'i"):
+			// The ezafe is not written after a consonant or a silent h
+			continue
+		case lang == Persian && j > 0 && part == "yi":
+			beam = extendSpellings(beam, []spelling{{text: "ی", score: 1, dictionary: true}}, "")
+			continue
+		}
+		spelled = true
+
+		if !last {
+			if prefix, ok := t.reverseProclitic(part, lang); ok {
+				beam = extendSpellings(beam, []spelling{{text: prefix, score: 1, dictionary: true}}, sep)
+				sep = ""
+				continue
+			}
+			if article != nil && part == "al" {
+				articlePending = true
+				continue
+			}
+			if rest, ok := sunArticle(part, next); ok && rest == "a" {
+				articlePending = true
+				continue
+			}
+		}
+
+		// The name of God after an elided article: Bahá'u'lláh, raḥmatu'lláh
+		if stem, ok := strings.CutSuffix(part, "'lláh"); ok && stem != "" {
+			beam = extendSpellings(beam, t.stemOptions(stem, lang, articlePending), sep)
+			beam = extendSpellings(beam, t.reverseStem("alláh", lang), " ")
+			articlePending = false
+			sep = " "
+			continue
+		}
+
+		// A word ending with the elided article: anta'l-, fí'd-
+		stem, elided := "", false
+		if !last && strings.HasSuffix(part, "'l") {
+			stem, elided = strings.TrimSuffix(part, "'l"), true
+		} else if rest, ok := sunArticle(part, next); ok && !last && strings.HasSuffix(rest, "'") {
+			stem, elided = strings.TrimSuffix(rest, "'"), true
+		}
+		if elided && stem != "" {
+			if prefix, ok := t.reverseProclitic(stem, lang); ok && !articlePending {
+				beam = extendSpellings(beam, []spelling{{text: prefix, score: 1, dictionary: true}}, sep)
+				sep = ""
+			} else {
+				beam = extendSpellings(beam, t.stemOptions(stem, lang, articlePending), sep)
+				sep = " "
+			}
+			articlePending = true
+			continue
+		}
+
+		beam = extendSpellings(beam, t.stemOptions(part, lang, articlePending), sep)
+		articlePending = false
+		sep = " "
+	}
+
+	if !spelled {
+		return nil
+	}
+	return beam
+}
+
+// reverseProclitic returns the spelling of a romanized proclitic (wa → و) or Persian
+// verbal prefix (mí → می, joined by a zero width non-joiner)
+func (t *Transliterator) reverseProclitic(part string, lang Language) (string, bool) {
+	for _, p := range t.proclitics[lang] {
+		if canonicalRoman(p.transliteration) == part {
+			return p.spelling, true
+		}
+	}
+	for spelling, entry := range t.dictionary(lang).VerbalPrefixes {
+		if strings.HasSuffix(entry.Transliteration, "-") && canonicalRoman(strings.TrimSuffix(entry.Transliteration, "-")) == part {
+			return spelling + string(zeroWidthNonJoiner), true
+		}
+	}
+	return "", false
+}
+
+// stemOptions spells a romanized word without proclitics, with the article when one
+// precedes it. The dictionary may list the word with its article (al-Muhaymin).
+func (t *Transliterator) stemOptions(stem string, lang Language, withArticle bool) []spelling {
+	article := t.articles[lang]
+	if article == nil {
+		article = t.articles[Arabic]
+	}
+	if !withArticle || article == nil {
+		return t.reverseStem(stem, lang)
+	}
+
+	options := t.dictionaryStem("al-"+stem, lang)
+	for _, s := range t.reverseStem(stem, lang) {
+		s.text = article.Pattern + s.text
+		s.score *= 0.9
+		options = append(options, s)
+	}
+	return options
+}
+
+// reverseStem spells a romanized word without clitics: from the dictionary when it
+// lists the word, letter by letter otherwise
+func (t *Transliterator) reverseStem(stem string, lang Language) []spelling {
+	if options := t.dictionaryStem(stem, lang); len(options) > 0 {
+		return options
+	}
+	return reverseLetters(stem, lang)
+}
+
+// dictionaryStem looks a romanized word up in the dictionary, also without the case
+// ending the dictionary leaves out (al-Muhayminu → al-Muhaymin)
+func (t *Transliterator) dictionaryStem(stem string, lang Language) []spelling {
+	idx := t.reverse[lang]
+	spellings := idx.exact[stem]
+	if len(spellings) == 0 {
+		for _, ending := range []string{"un", "in", "an", "u", "i", "a"} {
+			trimmed, ok := strings.CutSuffix(stem, ending)
+			if !ok {
+				continue
+			}
+			if len(idx.exact[trimmed]) > 0 {
+				spellings = idx.exact[trimmed]
+				break
+			}
+			// Ta marbuta in construct: raḥmatu → raḥmah
+			if pausal, ok := strings.CutSuffix(trimmed, "at"); ok && len(idx.exact[pausal+"ah"]) > 0 {
+				spellings = idx.exact[pausal+"ah"]
+				break
+			}
+		}
+	}
+
+	var options []spelling
+	for i, s := range spellings {
+		options = append(options, spelling{text: s, score: 1 - 0.01*float64(i), dictionary: true})
+	}
+	return options
+}
+
+// romanOption is one Arabic-script reading of a romanized letter
+type romanOption struct {
+	text  string
+	score float64
+}
+
+// romanConsonants gives the Arabic-script readings of each romanized consonant,
+// most likely first. Digraphs may also stand for two letters (adham, ashal).
+var romanConsonants = map[Language]map[string][]romanOption{
+	Arabic: {
+		"b": {{"ب", 1}}, "p": {{"پ", 1}}, "t": {{"ت", 1}}, "th": {{"ث", 1}, {"ته", 0.2}},
+		"j": {{"ج", 1}}, "ch": {{"چ", 1}}, "ḥ": {{"ح", 1}}, "kh": {{"خ", 1}, {"كه", 0.2}},
+		"d": {{"د", 1}}, "dh": {{"ذ", 1}, {"ده", 0.2}}, "r": {{"ر", 1}}, "z": {{"ز", 1}},
+		"zh": {{"ژ", 1}}, "s": {{"س", 1}}, "sh": {{"ش", 1}, {"سه", 0.2}}, "ṣ": {{"ص", 1}},
+		"ḍ": {{"ض", 1}}, "ṭ": {{"ط", 1}}, "ẓ": {{"ظ", 1}}, "gh": {{"غ", 1}},
+		"f": {{"ف", 1}}, "q": {{"ق", 1}}, "k": {{"ك", 1}}, "g": {{"گ", 1}}, "l": {{"ل", 1}},
+		"m": {{"م", 1}}, "n": {{"ن", 1}}, "h": {{"ه", 1}}, "w": {{"و", 1}}, "v": {{"و", 1}},
+		"y": {{"ي", 1}},
+	},
+	Persian: {
+		"b": {{"ب", 1}}, "p": {{"پ", 1}}, "t": {{"ت", 1}, {"ط", 0.3}}, "th": {{"ث", 1}, {"ته", 0.2}},
+		"j": {{"ج", 1}}, "ch": {{"چ", 1}}, "ḥ": {{"ح", 1}}, "kh": {{"خ", 1}, {"كه", 0.2}},
+		"d": {{"د", 1}}, "dh": {{"ذ", 1}, {"ده", 0.2}}, "r": {{"ر", 1}},
+		"z": {{"ز", 1}, {"ذ", 0.3}, {"ض", 0.2}, {"ظ", 0.2}}, "zh": {{"ژ", 1}},
+		"s": {{"س", 1}, {"ص", 0.3}, {"ث", 0.3}}, "sh": {{"ش", 1}, {"سه", 0.2}}, "ṣ": {{"ص", 1}},
+		"ḍ": {{"ض", 1}}, "ṭ": {{"ط", 1}}, "ẓ": {{"ظ", 1}}, "gh": {{"غ", 1}, {"ق", 0.3}},
+		"f": {{"ف", 1}}, "q": {{"ق", 1}}, "k": {{"ک", 1}}, "g": {{"گ", 1}}, "l": {{"ل", 1}},
+		"m": {{"م", 1}}, "n": {{"ن", 1}}, "h": {{"ه", 1}, {"ح", 0.3}}, "w": {{"و", 1}}, "v": {{"و", 1}},
+		"y": {{"ی", 1}},
+	},
+}
+
+// romanUnit is a letter of a romanized word: a consonant, a vowel or an apostrophe
+type romanUnit struct {
+	text  string
+	vowel bool
+	long  bool
+}
+
+// splitRomanUnits cuts a romanized word into letters, digraphs first
+func splitRomanUnits(word string, lang Language) []romanUnit {
+	consonants := romanConsonants[lang]
+	runes := []rune(word)
+	var units []romanUnit
+	for i := 0; i < len(runes); {
+		if i+1 < len(runes) {
+			if _, ok := consonants[string(runes[i:i+2])]; ok {
+				units = append(units, romanUnit{text: string(runes[i : i+2])})
+				i += 2
+				continue
+			}
+		}
+		r := string(runes[i])
+		switch {
+		case strings.Contains("áíú", r):
+			units = append(units, romanUnit{text: r, vowel: true, long: true})
+		case strings.Contains("aiueo", r):
+			units = append(units, romanUnit{text: r, vowel: true})
+		default:
+			units = append(units, romanUnit{text: r})
+		}
+		i++
+	}
+	return units
+}
+
+// reverseLetters spells a romanized word letter by letter. Short vowels are not
+// written, doubled consonants are written once (the shadda is left out), and the
+// hamza takes the seat its vowels call for.
+func reverseLetters(word string, lang Language) []spelling {
+	units := splitRomanUnits(word, lang)
+	yeh, alefMaqsura := "ي", "ى"
+	if lang == Persian {
+		yeh, alefMaqsura = "ی", "ی"
+	}
+
+	beam := []spelling{{score: 1}}
+	for i, u := range units {
+		first, last := i == 0, i == len(units)-1
+		var prev, next romanUnit
+		if i > 0 {
+			prev = units[i-1]
+		}
+		if !last {
+			next = units[i+1]
+		}
+
+		var options []romanOption
+		switch {
+		case u.text == "'":
+			// A hamza followed only by a case ending is final (Bahá'u → بهاء)
+			options = hamzaOptions(prev, next, first, last || onlyShortVowels(units[i+1:]))
+		case u.vowel && !u.long:
+			if first {
+				options = []romanOption{{"ا", 1}, {"أ", 0.6}}
+				if u.text == "i" {
+					options[1].text = "إ"
+				}
+			}
+		case u.text == "á":
+			switch {
+			case first:
+				options = []romanOption{{"آ", 1}, {"ا", 0.6}}
+			case last && lang == Arabic:
+				options = []romanOption{{"ا", 1}, {alefMaqsura, 0.6}}
+			default:
+				options = []romanOption{{"ا", 1}}
+			}
+		case u.text == "í":
+			options = []romanOption{{yeh, 1}}
+			if first {
+				options = []romanOption{{"ا" + yeh, 1}, {"إ" + yeh, 0.6}}
+			}
+		case u.text == "ú":
+			options = []romanOption{{"و", 1}}
+			if first {
+				options = []romanOption{{"او", 1}}
+			}
+		case !u.vowel && prev.text == u.text:
+			// Doubled consonant, written once
+		case (u.text == "y" && prev.text == "í") || (u.text == "w" && prev.text == "ú"):
+			// The long vowel already wrote the letter
+		case u.text == "t" && lang == Arabic && !last && onlyShortVowels(units[i+1:]) && prev.text == "a":
+			// Ta marbuta in construct, before a case ending (raḥmatu'lláh)
+			options = []romanOption{{"ة", 0.6}, {"ت", 0.5}}
+		case u.text == "h" && last && i > 0 && prev.vowel && !prev.long:
+			if lang == Arabic {
+				options = []romanOption{{"ة", 0.7}, {"ه", 0.5}}
+			} else {
+				options = []romanOption{{"ه", 1}}
+			}
+		default:
+			options = romanConsonants[lang][u.text]
+			if options == nil {
+				// Not a letter of the scheme: dropped, at a cost
+				options = []romanOption{{"", 0.5}}
+			}
+		}
+
+		if options == nil {
+			continue
+		}
+		var spellings []spelling
+		for _, o := range options {
+			spellings = append(spellings, spelling{text: o.text, score: o.score})
+		}
+		beam = extendSpellings(beam, spellings, "")
+	}
+
+	if len(beam) > maxCandidates {
+		beam = beam[:maxCandidates]
+	}
+	return beam
+}
+
+// onlyShortVowels reports whether units holds nothing but short vowels
+func onlyShortVowels(units []romanUnit) bool {
+	for _, u := range units {
+		if !u.vowel || u.long {
+			return false
+		}
+	}
+	return true
+}
+
+// hamzaOptions gives the readings of an apostrophe: ʿayn, or hamza on the seat its
+// neighbouring vowels call for. At the start of a word it is ʿayn, since the Bahá'í
+// scheme does not write an initial hamza.
+func hamzaOptions(prev, next romanUnit, first, last bool) []romanOption {
+	if first {
+		return []romanOption{{"ع", 1}}
+	}
+
+	seat := "أ"
+	switch {
+	case last || (prev.long && prev.text == "á" && !next.vowel):
+		seat = "ء"
+	case strings.ContainsAny(prev.text+next.text, "ií"):
+		seat = "ئ"
+	case strings.ContainsAny(prev.text+next.text, "uú"):
+		seat = "ؤ"
+	}
+	if seat == "ء" && prev.long {
+		// Words ending in a long vowel and hamza are the more common (samá', shifá')
+		return []romanOption{{seat, 0.6}, {"ع", 0.5}}
+	}
+	return []romanOption{{"ع", 0.6}, {seat, 0.5}}
+}
+
+// uniqueSorted returns the distinct strings of list in sort order
+func uniqueSorted(list []string) []string {
+	sort.Strings(list)
+	var result []string
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
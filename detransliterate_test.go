@@ -0,0 +1,105 @@
+package transliterator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetransliterate(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		roman    string
+		lang     Language
+		expected string // best candidate
+	}{
+		{"dictionary phrase", "Yá Iláhí,", Arabic, "يا إلهي،"},
+		{"dictionary word", "shifá'í", Arabic, "شفائي"},
+		{"macrons and other apostrophes", "shifāʾī", Arabic, "شفائي"},
+		{"proclitic", "wa-dhikruka", Arabic, "وذكرك"},
+		{"elided article", "wa'l-'álamín", Arabic, "والعالمين"},
+		{"sun letter article", "ar-Raḥmán", Arabic, "الرحمن"},
+		{"case ending", "anta'l-Muhayminu'l-Qayyúm.", Arabic, "أنت المهيمن القيوم."},
+		{"name of god", "raḥmatu'lláh", Arabic, "رحمة الله"},
+		{"final hamza", "Bahá'u'lláh", Arabic, "بهاء الله"},
+		{"letters", "tajallíyát", Arabic, "تجليات"},
+		{"ta marbuta", "ni'mah", Arabic, "نعمة"},
+		{"persian ezafe", "baḥr-i 'aṭá", Persian, "بحر عطا"},
+		{"persian ezafe after a vowel", "Khudá-yi man", Persian, "خدای من"},
+		{"persian verbal prefix", "mí-dihad", Persian, "می‌دهد"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := trans.Detransliterate(tt.roman, tt.lang)
+			if err != nil {
+				t.Fatalf("Detransliterate(%q) failed: %v", tt.roman, err)
+			}
+			var best []string
+			for _, w := range words {
+				if len(w.Candidates) == 0 {
+					t.Fatalf("no candidates for %q", w.Roman)
+				}
+				best = append(best, w.Candidates[0].Text)
+			}
+			if result := strings.Join(best, " "); result != tt.expected {
+				t.Errorf("Detransliterate(%q) = %q, expected %q (%+v)", tt.roman, result, tt.expected, words)
+			}
+		})
+	}
+
+	// Ambiguous letters give several ranked candidates
+	words, _ := trans.Detransliterate("sa'ala", Arabic)
+	if len(words) != 1 || len(words[0].Candidates) < 2 {
+		t.Fatalf("expected several candidates for sa'ala, got %+v", words)
+	}
+	found := false
+	for i, c := range words[0].Candidates {
+		found = found || c.Text == "سأل"
+		if i > 0 && c.Score > words[0].Candidates[i-1].Score {
+			t.Errorf("candidates are not ranked: %+v", words[0].Candidates)
+		}
+	}
+	if !found {
+		t.Errorf("candidates for sa'ala = %+v, expected سأل among them", words[0].Candidates)
+	}
+
+	// Punctuation and numbers are kept as they are, and do not count as dictionary words
+	words, _ = trans.Detransliterate("Yá Iláhí , 1844", Arabic)
+	for _, w := range words[len(words)-2:] {
+		expected := []Candidate{{Text: w.Roman, Score: 1}}
+		if !reflect.DeepEqual(w.Candidates, expected) {
+			t.Errorf("candidates for %q = %+v, expected %+v", w.Roman, w.Candidates, expected)
+		}
+	}
+
+	if _, err := trans.Detransliterate("salám", Language(7)); err == nil {
+		t.Errorf("Detransliterate should reject an unknown language")
+	}
+}
+
+func TestDetransliterateRoundTrip(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	text := "يا إلهي اسمك شفائي وذكرك دوائي"
+	words, err := trans.Detransliterate(trans.Transliterate(text, Arabic), Arabic)
+	if err != nil {
+		t.Fatalf("Detransliterate failed: %v", err)
+	}
+	var best []string
+	for _, w := range words {
+		best = append(best, w.Candidates[0].Text)
+	}
+	// The phrase keeps the comma its transliteration adds
+	if result := strings.Join(best, " "); result != "يا إلهي، اسمك شفائي وذكرك دوائي" {
+		t.Errorf("round trip of %q = %q", text, result)
+	}
+}
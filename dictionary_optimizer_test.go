@@ -20,6 +20,7 @@ type TestCases struct {
 	CommonWordsTest   []WordTest     `json:"common_words_test"`
 	HeuristicTestWords []WordTest    `json:"heuristic_test_words"`
 	EzafeTestCases    []EzafeTest    `json:"ezafe_test_cases"`
+	ArticleTestWords  []WordTest     `json:"article_test_words"`
 }
 
 type TestCase struct {
@@ -0,0 +1,136 @@
+package transliterator
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// Marks that write the Persian ezafe on the word itself
+const (
+	kasra              = 'ِ'
+	hamzaAbove         = 'ٔ'
+	hehWithYeh         = 'ۀ'
+	heh                = 'ه'
+	zeroWidthNonJoiner = '‌'
+)
+
+// ezafeRules is the parsed ezafe_rules section of a dictionary
+type ezafeRules struct {
+	Connector       string            `json:"connector"`
+	BeforeConsonant string            `json:"before_consonant"`
+	BeforeVowel     string            `json:"before_vowel"`
+	AfterSilentH    string            `json:"after_silent_h"`
+	Examples        map[string]string `json:"examples"`
+
+	hints map[string]bool // first words of the example pairs, keyed by "first second"
+}
+
+// parseEzafeRules extracts the ezafe rules from a dictionary, if it has them
+func parseEzafeRules(dict *Dictionary) *ezafeRules {
+	if dict.EzafeRules == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(dict.EzafeRules)
+	if err != nil {
+		return nil
+	}
+
+	rules := &ezafeRules{}
+	if err := json.Unmarshal(data, rules); err != nil || rules.BeforeConsonant == "" {
+		return nil
+	}
+	if rules.BeforeVowel == "" {
+		rules.BeforeVowel = rules.BeforeConsonant
+	}
+	if rules.AfterSilentH == "" {
+		rules.AfterSilentH = rules.BeforeConsonant
+	}
+
+	rules.hints = make(map[string]bool, len(rules.Examples))
+	for pair := range rules.Examples {
+		rules.hints[strings.Join(strings.Fields(pair), " ")] = true
+	}
+
+	return rules
+}
+
+// splitEzafeMark removes an explicit ezafe mark from the end of a Persian word:
+// a kasra on the final letter (سزاوارِ) or a hamza/yeh on a final silent h (نملۀ).
+// Trailing punctuation is kept in place.
+func (t *Transliterator) splitEzafeMark(word string, lang Language) (string, bool) {
+	if t.ezafes[lang] == nil {
+		return word, false
+	}
+
+	core, punct := splitTrailingPunctuation(word)
+	runes := []rune(core)
+	if len(runes) < 2 {
+		return word, false
+	}
+
+	switch last := runes[len(runes)-1]; {
+	case last == kasra || last == hamzaAbove && runes[len(runes)-2] == heh:
+		return string(runes[:len(runes)-1]) + punct, true
+	case last == hehWithYeh:
+		return string(runes[:len(runes)-1]) + string(heh) + punct, true
+	}
+
+	return word, false
+}
+
+// applyPersianEzafe appends the ezafe allomorph to tokens marked with an explicit
+// ezafe and to the first word of pairs the dictionary lists as ezafe examples
+func (t *Transliterator) applyPersianEzafe(tokens []token, lang Language) []token {
+	rules := t.ezafes[lang]
+	if rules == nil {
+		return tokens
+	}
+
+	for i := range tokens {
+		ezafe := tokens[i].ezafe
+		if !ezafe && i+1 < len(tokens) {
+			first, _ := splitTrailingPunctuation(t.removeDiacritics(tokens[i].source))
+			second, _ := splitTrailingPunctuation(t.removeDiacritics(tokens[i+1].source))
+			ezafe = rules.hints[first+" "+second]
+		}
+		if !ezafe {
+			continue
+		}
+
+		output, punct := splitTrailingPunctuation(tokens[i].output)
+		tokens[i].output = output + rules.allomorph(t.removeDiacritics(tokens[i].source), output) + punct
+	}
+
+	return tokens
+}
+
+// allomorph chooses the ezafe form from how the word and its transliteration end
+func (r *ezafeRules) allomorph(source, output string) string {
+	source, _ = splitTrailingPunctuation(source)
+	if strings.HasSuffix(source, string(hehWithYeh)) || isSilentH(source, output) {
+		return r.AfterSilentH
+	}
+	if endsWithVowel(output) {
+		return r.BeforeVowel
+	}
+	return r.BeforeConsonant
+}
+
+// isSilentH reports whether a word ends in an unpronounced h written as ه,
+// which shows up as a short vowel plus h in the transliteration (namúdih, but not ráh)
+func isSilentH(source, output string) bool {
+	if !strings.HasSuffix(source, string(heh)) || len([]rune(source)) < 2 {
+		return false
+	}
+	return strings.HasSuffix(output, "ih") || strings.HasSuffix(output, "ah")
+}
+
+// splitTrailingPunctuation separates trailing punctuation and symbols from s
+func splitTrailingPunctuation(s string) (string, string) {
+	core := strings.TrimRightFunc(s, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	return core, s[len(core):]
+}
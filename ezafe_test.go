@@ -0,0 +1,39 @@
+package transliterator
+
+import "testing"
+
+func TestPersianEzafe(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"kasra after consonant", "سزاوارِ ایام", "sizávár-i ayyám"},
+		{"kasra before ayn", "بحرِ عطا", "baḥr-i 'aṭá"},
+		{"kasra after long h", "راهِ تو", "ráh-i tú"},
+		{"kasra after long vowel", "آگاهیِ من", "ágáhí-yi man"},
+		{"heh with yeh after silent h", "نملۀ فانیه", "namlih-'i fániyih"},
+		{"hamza above after silent h", "مشاهدهٔ تو", "musháhidih-'i tú"},
+		{"dictionary example pair", "پروردگار من", "Parvardigár-i man"},
+		{"no ezafe without mark or hint", "پروردگار تو", "Parvardigár tú"},
+		{"dictionary entry already carries ezafe", "فنای تو", "faná-yi tú"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trans.Transliterate(tt.input, Persian); got != tt.expected {
+				t.Errorf("Ezafe failed for %s\nExpected: %s\nGot: %s", tt.input, tt.expected, got)
+			}
+		})
+	}
+
+	// Arabic has no ezafe rules, so a final kasra is an ordinary case vowel
+	if got := trans.Transliterate("بحرِ", Arabic); got == "baḥr-i" {
+		t.Errorf("Ezafe should not apply to Arabic, got %s", got)
+	}
+}
@@ -1,3 +1,10 @@
 module github.com/LaPingvino/bahai-transliterator
 
 go 1.21
+
+require (
+	github.com/go-sql-driver/mysql v1.8.1
+	golang.org/x/text v0.22.0
+)
+
+require filippo.io/edwards25519 v1.1.0 // indirect
@@ -0,0 +1,145 @@
+package transliterator
+
+import "strings"
+
+// IrabPolicy decides which case endings (i'ráb) of vocalized Arabic words are kept:
+// the final short vowels and tanwin written on the last letter
+type IrabPolicy int
+
+const (
+	// IrabFull keeps every case ending written in the text (anta'l-Muqtadiru'l-Qadíru)
+	IrabFull IrabPolicy = iota
+	// IrabPausal drops case endings in pause: before punctuation and at the end of a
+	// line or of the text (anta'l-Muqtadiru'l-Qadír)
+	IrabPausal
+	// IrabNone drops all case endings (anta'l-Muqtadir al-Qadír)
+	IrabNone
+)
+
+// String returns the name of the policy
+func (p IrabPolicy) String() string {
+	switch p {
+	case IrabFull:
+		return "full"
+	case IrabPausal:
+		return "pausal"
+	case IrabNone:
+		return "none"
+	}
+	return "unknown"
+}
+
+// WithIrabPolicy sets how case endings are treated; the default is IrabFull
+func WithIrabPolicy(policy IrabPolicy) Option {
+	return func(o *options) {
+		o.irab = policy
+	}
+}
+
+// caseEndings gives the transliteration of each case ending mark
+var caseEndings = map[rune]string{
+	fatha: "a", kasra: "i", damma: "u",
+	fathatan: "an", kasratan: "in", dammatan: "un",
+}
+
+// applyIrabPolicy replaces case endings with pausal forms where the policy asks for it.
+// It runs before applyArticleElision, so a word that loses its ending no longer
+// contracts with a following article (al-Muqtadir al-Qadír).
+func (t *Transliterator) applyIrabPolicy(tokens []token, lang Language) []token {
+	if lang != Arabic || t.irab == IrabFull {
+		return tokens
+	}
+
+	for i := range tokens {
+		// Phrase entries are written the way the dictionary wants them
+		if tokens[i].stage == StagePhrase || (t.irab == IrabPausal && !isPausePosition(tokens, i)) {
+			continue
+		}
+		output, punct := splitTrailingPunctuation(tokens[i].output)
+		tokens[i].output = t.pausalForm(tokens[i].source, output) + punct
+	}
+
+	return tokens
+}
+
+// isPausePosition reports whether tokens[i] ends a phrase: it carries trailing
+// punctuation, ends its line, or is followed by nothing but punctuation
+func isPausePosition(tokens []token, i int) bool {
+	if tokens[i].lineEnd || i == len(tokens)-1 {
+		return true
+	}
+	if _, punct := splitTrailingPunctuation(tokens[i].source); punct != "" {
+		return true
+	}
+	next, _ := splitTrailingPunctuation(tokens[i+1].source)
+	return next == ""
+}
+
+// pausalForm drops the case ending of a vocalized word from its transliteration.
+// Final vowels that belong to the word itself are kept: those of pronouns and
+// particles such as anta and laka and those of pronominal suffixes (raḥmatuka).
+func (t *Transliterator) pausalForm(source, output string) string {
+	source, _ = splitTrailingPunctuation(source)
+	mark, carrier := caseEnding(parseVocalized(source))
+	ending := caseEndings[mark]
+	if ending == "" || !strings.HasSuffix(output, ending) {
+		return output
+	}
+
+	clean := t.removeDiacritics(source)
+	if entry, _, exists := t.lookupWord(clean, Arabic); exists && hasFixedEnding(entry.Category) {
+		return output
+	}
+	if segmentation, ok := t.segmentClitics(clean); ok && segmentation.suffixTrans != "" {
+		return output
+	}
+
+	pausal := strings.TrimSuffix(output, ending)
+	if carrier == taMarbuta {
+		// In pause the ta marbuta is pronounced h (raḥmatun → raḥmah)
+		pausal = t.taMarbutaForm(pausal, Arabic, false)
+	} else if mark == fathatan {
+		// The accusative tanwin lengthens to á in pause (abadan → abadá)
+		pausal += "á"
+	}
+
+	return pausal
+}
+
+// fixedEndingCategories are the dictionary categories whose final vowel is part of
+// the word rather than a case ending
+var fixedEndingCategories = []string{
+	"pronoun", "particle", "conjunction", "preposition", "demonstrative", "relative", "noun_suffix",
+}
+
+// hasFixedEnding reports whether words of a dictionary category keep their final vowel
+func hasFixedEnding(category string) bool {
+	for _, prefix := range fixedEndingCategories {
+		if strings.HasPrefix(category, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// caseEnding returns the case ending mark at the end of a word and the letter that
+// carries it. The alef written after the accusative tanwin (كتابًا، كتاباً) is skipped.
+func caseEnding(letters []vocalizedLetter) (mark rune, carrier rune) {
+	n := len(letters)
+	if n == 0 {
+		return 0, 0
+	}
+
+	last := letters[n-1]
+	if last.letter == 'ا' && n > 1 {
+		before := letters[n-2]
+		if last.vowel == fathatan || (last.vowel == 0 && before.vowel == fathatan) {
+			return fathatan, before.letter
+		}
+	}
+
+	if last.sukun {
+		return 0, 0
+	}
+	return last.vowel, last.letter
+}
@@ -0,0 +1,37 @@
+package transliterator
+
+import "testing"
+
+func TestIrabPolicy(t *testing.T) {
+	tests := []struct {
+		policy   IrabPolicy
+		input    string
+		expected string
+	}{
+		{IrabFull, "أَنْتَ الْمُقْتَدِرُ الْقَدِيرُ", "anta'l-Muqtadiru'l-Qadíru"},
+		{IrabPausal, "أَنْتَ الْمُقْتَدِرُ الْقَدِيرُ", "anta'l-Muqtadiru'l-Qadír"},
+		{IrabNone, "أَنْتَ الْمُقْتَدِرُ الْقَدِيرُ", "anta'l-Muqtadir al-Qadír"},
+
+		// Pause before punctuation and at the end of a line
+		{IrabPausal, "الْحَمْدُ، وَالْقَيُّومُ.", "al-ḥamd, wa'l-Qayyúm."},
+		{IrabPausal, "كِتَابٌ كَبِيرٌ\nلَكَ الْحَمْدُ", "kitábun kabír laka'l-ḥamd"},
+
+		// Tanwin: the accusative lengthens, ta marbuta is read h
+		{IrabNone, "كِتَابًا", "kitábá"},
+		{IrabNone, "رَحْمَةً", "raḥmah"},
+
+		// Vowels of pronouns, particles and pronominal suffixes are not case endings
+		{IrabNone, "هُوَ", "huwa"},
+		{IrabNone, "وَقُوَّتِكَ", "wa-quwwatika"},
+	}
+
+	for _, tt := range tests {
+		trans, err := NewWithOptions(WithIrabPolicy(tt.policy))
+		if err != nil {
+			t.Fatalf("Failed to create transliterator: %v", err)
+		}
+		if got := trans.Transliterate(tt.input, Arabic); got != tt.expected {
+			t.Errorf("%v: Transliterate(%q) = %q, expected %q", tt.policy, tt.input, got, tt.expected)
+		}
+	}
+}
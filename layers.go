@@ -0,0 +1,178 @@
+package transliterator
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// BaseLayer is the name of the built-in dictionary at the bottom of every layer stack
+const BaseLayer = "base"
+
+// Layer is a named dictionary stacked on top of the base dictionary for one language.
+// Entries in later layers override CommonWords, DivineNames and CommonPhrases entries
+// of earlier layers, across sections: a divine name in a layer overrides a common word
+// of the base. The remaining sections (rules, patterns) always come from the base.
+type Layer struct {
+	Name       string
+	Language   Language
+	Dictionary *Dictionary
+}
+
+// layerIndex records which layer supplied each merged dictionary entry
+type layerIndex struct {
+	names         []string
+	commonWords   map[string]string
+	divineNames   map[string]string
+	commonPhrases map[string]string
+}
+
+// WithLayers stacks additional dictionaries (community, project, ...) on top of the
+// base dictionaries, in the given order
+func WithLayers(layers ...Layer) Option {
+	return func(o *options) {
+		o.layers = append(o.layers, layers...)
+	}
+}
+
+// LoadDictionary reads a JSON dictionary from fsys, e.g. to use it as a Layer
+func LoadDictionary(fsys fs.FS, path string) (*Dictionary, error) {
+	return readDictionary(fsys, path)
+}
+
+// applyLayers merges the configured layers into the base dictionaries
+func (t *Transliterator) applyLayers(layers []Layer) error {
+	var arabicLayers, persianLayers []Layer
+	seen := map[string]bool{BaseLayer: true}
+
+	for i, layer := range layers {
+		if layer.Name == "" {
+			return fmt.Errorf("layer %d has no name", i)
+		}
+		if seen[layer.Name] {
+			return fmt.Errorf("duplicate layer name %q", layer.Name)
+		}
+		if layer.Dictionary == nil {
+			return fmt.Errorf("layer %q has no dictionary", layer.Name)
+		}
+		seen[layer.Name] = true
+
+		switch layer.Language {
+		case Arabic:
+			arabicLayers = append(arabicLayers, layer)
+		case Persian:
+			persianLayers = append(persianLayers, layer)
+		default:
+			return fmt.Errorf("layer %q has unknown language %d", layer.Name, layer.Language)
+		}
+	}
+
+	t.layers = map[Language]*layerIndex{}
+	t.arabicDict, t.layers[Arabic] = mergeLayers(t.arabicDict, arabicLayers)
+	t.persianDict, t.layers[Persian] = mergeLayers(t.persianDict, persianLayers)
+
+	return nil
+}
+
+// mergeLayers builds the lookup dictionary for one language from base and its layers
+func mergeLayers(base *Dictionary, layers []Layer) (*Dictionary, *layerIndex) {
+	merged := *base
+	merged.CommonWords = make(map[string]WordEntry, len(base.CommonWords))
+	merged.DivineNames = make(map[string]WordEntry, len(base.DivineNames))
+	merged.CommonPhrases = make(map[string]Pattern, len(base.CommonPhrases))
+
+	idx := &layerIndex{
+		commonWords:   make(map[string]string),
+		divineNames:   make(map[string]string),
+		commonPhrases: make(map[string]string),
+	}
+
+	stack := append([]Layer{{Name: BaseLayer, Dictionary: base}}, layers...)
+	for _, layer := range stack {
+		idx.names = append(idx.names, layer.Name)
+
+		for word, entry := range layer.Dictionary.CommonWords {
+			merged.CommonWords[word] = entry
+			idx.commonWords[word] = layer.Name
+		}
+		for word, entry := range layer.Dictionary.DivineNames {
+			merged.DivineNames[word] = entry
+			idx.divineNames[word] = layer.Name
+		}
+		for phrase, entry := range layer.Dictionary.CommonPhrases {
+			merged.CommonPhrases[phrase] = entry
+			idx.commonPhrases[phrase] = layer.Name
+		}
+	}
+
+	return &merged, idx
+}
+
+// rank returns the position of the named layer in the stack, base first
+func (idx *layerIndex) rank(name string) int {
+	for i, n := range idx.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// dictionaryStage tells whether lookupWord finds cleanWord among the common words
+// or the divine names
+func (t *Transliterator) dictionaryStage(cleanWord string, lang Language) Stage {
+	if _, _, stage, exists := t.lookupEntry(cleanWord, lang); exists {
+		return stage
+	}
+	return StageCommonWord
+}
+
+// Layers returns the names of the dictionary layers for lang, base first
+func (t *Transliterator) Layers(lang Language) []string {
+	return append([]string(nil), t.layers[lang].names...)
+}
+
+// Lookup returns the dictionary entry used for word and the name of the layer
+// that produced it. As in Transliterate, the entry of the latest layer wins, and
+// within one layer common words take priority over divine names.
+func (t *Transliterator) Lookup(word string, lang Language) (WordEntry, string, bool) {
+	return t.lookupWord(t.removeDiacritics(normalizeText(word)), lang)
+}
+
+// LookupPhrase returns the phrase entry for phrase and the name of the layer that produced it
+func (t *Transliterator) LookupPhrase(phrase string, lang Language) (Pattern, string, bool) {
+	entry, exists := t.dictionary(lang).CommonPhrases[phrase]
+	if !exists {
+		return Pattern{}, "", false
+	}
+	return entry, t.layers[lang].commonPhrases[phrase], true
+}
+
+// lookupWord finds a diacritic-free word in the merged common words and divine names
+func (t *Transliterator) lookupWord(cleanWord string, lang Language) (WordEntry, string, bool) {
+	entry, layer, _, exists := t.lookupEntry(cleanWord, lang)
+	return entry, layer, exists
+}
+
+// lookupEntry is lookupWord that also tells which section the entry came from. A word
+// in both sections takes the entry of the later layer, or the common word if both
+// come from the same layer.
+func (t *Transliterator) lookupEntry(cleanWord string, lang Language) (WordEntry, string, Stage, bool) {
+	dict := t.dictionary(lang)
+	idx := t.layers[lang]
+
+	common, isCommon := dict.CommonWords[cleanWord]
+	divine, isDivine := dict.DivineNames[cleanWord]
+	if isDivine && (!isCommon || idx.rank(idx.divineNames[cleanWord]) > idx.rank(idx.commonWords[cleanWord])) {
+		return divine, idx.divineNames[cleanWord], StageDivineName, true
+	}
+	if isCommon {
+		return common, idx.commonWords[cleanWord], StageCommonWord, true
+	}
+
+	// Spelling variants (ي and ی, ك and ک, hamza seats) find the word through its match key
+	if spelling, exists := t.matchKeys[lang][matchKey(cleanWord)]; exists && spelling != cleanWord {
+		return t.lookupEntry(spelling, lang)
+	}
+
+	return WordEntry{}, "", "", false
+}
@@ -0,0 +1,147 @@
+package transliterator
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDictionaryLayers(t *testing.T) {
+	community := &Dictionary{
+		CommonWords: map[string]WordEntry{
+			"بدیع": {Transliteration: "Badí'", Category: "proper_name"},
+			"خدا":  {Transliteration: "khudá", Category: "divine_name"},
+		},
+	}
+	project := &Dictionary{
+		CommonWords: map[string]WordEntry{
+			"خدا": {Transliteration: "KHUDÁ", Category: "divine_name"},
+		},
+		CommonPhrases: map[string]Pattern{
+			"لوح احمد": {Transliteration: "Lawḥ-i-Aḥmad"},
+		},
+	}
+
+	trans, err := NewWithOptions(WithLayers(
+		Layer{Name: "community", Language: Persian, Dictionary: community},
+		Layer{Name: "project", Language: Persian, Dictionary: project},
+	))
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	if got := trans.Layers(Persian); len(got) != 3 || got[0] != BaseLayer || got[2] != "project" {
+		t.Errorf("Unexpected Persian layer stack: %v", got)
+	}
+	if got := trans.Layers(Arabic); len(got) != 1 || got[0] != BaseLayer {
+		t.Errorf("Unexpected Arabic layer stack: %v", got)
+	}
+
+	tests := []struct {
+		word     string
+		expected string
+		layer    string
+	}{
+		{"خدا", "KHUDÁ", "project"},    // project overrides community and base
+		{"بدیع", "Badí'", "community"}, // only in community
+		{"پروردگار", "Parvardigár", BaseLayer},
+	}
+
+	for _, tt := range tests {
+		entry, layer, ok := trans.Lookup(tt.word, Persian)
+		if !ok || entry.Transliteration != tt.expected || layer != tt.layer {
+			t.Errorf("Lookup(%s) = %q from %q (found %v), expected %q from %q",
+				tt.word, entry.Transliteration, layer, ok, tt.expected, tt.layer)
+		}
+		if got := trans.Transliterate(tt.word, Persian); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.word, got, tt.expected)
+		}
+	}
+
+	if _, layer, ok := trans.LookupPhrase("لوح احمد", Persian); !ok || layer != "project" {
+		t.Errorf("Expected phrase from project layer, got %q (found %v)", layer, ok)
+	}
+
+	// Layers must not leak into the base dictionary of a later transliterator
+	plain, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	if _, _, ok := plain.Lookup("بدیع", Persian); ok {
+		t.Errorf("Layer entry leaked into a transliterator without layers")
+	}
+}
+
+func TestDivineNameLayerOverridesCommonWord(t *testing.T) {
+	// العالمين is a common word and a divine name of the base; the common word wins
+	base, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	baseEntry, _, _ := base.Lookup("العالمين", Arabic)
+
+	layer := &Dictionary{
+		DivineNames: map[string]WordEntry{
+			"العالمين": {Transliteration: "al-'Álamín-override", Category: "divine_name"},
+		},
+	}
+	trans, err := NewWithOptions(WithLayers(Layer{Name: "project", Language: Arabic, Dictionary: layer}))
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	entry, name, ok := trans.Lookup("العالمين", Arabic)
+	if !ok || entry.Transliteration != "al-'Álamín-override" || name != "project" {
+		t.Errorf("Lookup(العالمين) = %q from %q, expected the divine name of the project layer", entry.Transliteration, name)
+	}
+	if got := trans.Transliterate("العالمين", Arabic); got != "al-'Álamín-override" {
+		t.Errorf("Transliterate(العالمين) = %q, expected the layer override", got)
+	}
+	if result, _ := trans.TransliterateWithReport("العالمين", Arabic); result.Tokens[0].Stage != StageDivineName || result.Tokens[0].Layer != "project" {
+		t.Errorf("report = %+v, expected a divine name from the project layer", result.Tokens[0])
+	}
+
+	// Without the layer the base common word is unchanged
+	if entry, name, _ := base.Lookup("العالمين", Arabic); entry != baseEntry || name != BaseLayer {
+		t.Errorf("base Lookup(العالمين) = %+v from %q", entry, name)
+	}
+}
+
+func TestDictionaryLayerFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"names.json": {Data: []byte(`{"common_words": {"بهاءالله": {"transliteration": "Bahá'u'lláh"}}}`)},
+	}
+	dict, err := LoadDictionary(fsys, "names.json")
+	if err != nil {
+		t.Fatalf("Failed to load layer dictionary: %v", err)
+	}
+
+	trans, err := NewWithOptions(WithLayers(Layer{Name: "names", Language: Arabic, Dictionary: dict}))
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	if got := trans.Transliterate("بهاءالله", Arabic); got != "Bahá'u'lláh" {
+		t.Errorf("Expected Bahá'u'lláh from layer, got %s", got)
+	}
+}
+
+func TestDictionaryLayerValidation(t *testing.T) {
+	dict := &Dictionary{}
+	tests := []struct {
+		name   string
+		layers []Layer
+	}{
+		{"missing name", []Layer{{Language: Arabic, Dictionary: dict}}},
+		{"reserved name", []Layer{{Name: BaseLayer, Language: Arabic, Dictionary: dict}}},
+		{"duplicate name", []Layer{{Name: "a", Dictionary: dict}, {Name: "a", Dictionary: dict}}},
+		{"missing dictionary", []Layer{{Name: "a", Language: Arabic}}},
+		{"unknown language", []Layer{{Name: "a", Language: Language(7), Dictionary: dict}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWithOptions(WithLayers(tt.layers...)); err == nil {
+				t.Errorf("Expected error for %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package transliterator
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuffixDepth limits how many suffixes are peeled off one word (kaníz+án+at)
+const maxSuffixDepth = 3
+
+// suffixRule is one entry of the suffixes section of a dictionary
+type suffixRule struct {
+	suffix          string
+	transliteration string
+	group           string // e.g. possessive, plural
+}
+
+// parseSuffixes flattens the suffix groups of a dictionary, longest suffix first
+func parseSuffixes(dict *Dictionary) []suffixRule {
+	var rules []suffixRule
+	for group, raw := range dict.Suffixes {
+		entries, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for suffix, rawEntry := range entries {
+			entry, ok := rawEntry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			trans, ok := entry["transliteration"].(string)
+			if !ok || suffix == "" || trans == "" {
+				continue
+			}
+			rules = append(rules, suffixRule{suffix: suffix, transliteration: trans, group: group})
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		li, lj := utf8.RuneCountInString(rules[i].suffix), utf8.RuneCountInString(rules[j].suffix)
+		if li != lj {
+			return li > lj
+		}
+		return rules[i].suffix < rules[j].suffix
+	})
+
+	return rules
+}
+
+// analyzeMorphology decomposes a diacritic-free word into a dictionary stem plus
+// affixes from the dictionary, trying the longest suffix first
+func (t *Transliterator) analyzeMorphology(word string, lang Language) string {
+	if lang == Arabic {
+		return t.analyzeClitics(word)
+	}
+	return t.decomposeSuffixes(word, lang, maxSuffixDepth)
+}
+
+// decomposeSuffixes peels one suffix off word and transliterates the stem, recursing
+// when the stem itself carries a suffix
+func (t *Transliterator) decomposeSuffixes(word string, lang Language, depth int) string {
+	if depth == 0 {
+		return ""
+	}
+
+	for _, rule := range t.suffixes[lang] {
+		if !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+		stem := strings.TrimSuffix(word, rule.suffix)
+		if utf8.RuneCountInString(stem) < 2 {
+			continue
+		}
+
+		if stemTrans := t.suffixStem(stem, lang, depth); stemTrans != "" {
+			return joinSuffix(stem, stemTrans, rule.transliteration)
+		}
+
+		// A ی before a vowel-initial suffix is the glide after a vowel-final stem (خدایان)
+		if glideless, ok := trimGlide(stem); ok && startsWithVowel(rule.transliteration) {
+			if stemTrans := t.suffixStem(glideless, lang, depth); stemTrans != "" && endsWithVowel(stemTrans) {
+				return joinSuffix(glideless, stemTrans, rule.transliteration)
+			}
+		}
+	}
+
+	return ""
+}
+
+// suffixStem transliterates a stem left after removing a suffix, from the dictionary
+// or by further decomposition
+func (t *Transliterator) suffixStem(stem string, lang Language, depth int) string {
+	if entry, _, exists := t.lookupWord(stem, lang); exists {
+		return entry.Transliteration
+	}
+	return t.decomposeSuffixes(stem, lang, depth-1)
+}
+
+// joinSuffix attaches a suffix transliteration to its stem with the right glide:
+// y after a vowel (Khudáyam), a hyphen after a silent h (namúdih-am), nothing otherwise
+func joinSuffix(stem, stemTrans, suffixTrans string) string {
+	if !startsWithVowel(suffixTrans) {
+		return stemTrans + suffixTrans
+	}
+	if isSilentH(stem, stemTrans) {
+		return stemTrans + "-" + suffixTrans
+	}
+	if endsWithVowel(stemTrans) {
+		return stemTrans + "y" + suffixTrans
+	}
+	return stemTrans + suffixTrans
+}
+
+// trimGlide removes a final Persian or Arabic ya written as a glide
+func trimGlide(stem string) (string, bool) {
+	for _, ya := range []string{"ی", "ي"} {
+		if strings.HasSuffix(stem, ya) {
+			return strings.TrimSuffix(stem, ya), true
+		}
+	}
+	return stem, false
+}
+
+// startsWithVowel reports whether a transliteration starts with a vowel
+func startsWithVowel(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return isVowel(r)
+}
@@ -0,0 +1,34 @@
+package transliterator
+
+import "testing"
+
+func TestPersianSuffixes(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"کنیزانت", "kanízánat"}, // plural stem + possessive
+		{"کنیزانش", "kanízánash"},
+		{"جانم", "jánam"},
+		{"اسمت", "ismat"},
+		{"خدایم", "Khudáyam"},  // glide after a vowel-final stem
+		{"خدایان", "Khudáyán"}, // ی glide written before the plural
+		{"بحرها", "baḥrhá"},    // consonant-initial suffix
+	}
+
+	for _, tt := range tests {
+		if got := trans.Transliterate(tt.input, Persian); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Stems that are not in the dictionary are left to the heuristic
+	if got := trans.analyzeMorphology("دست", Persian); got != "" {
+		t.Errorf("Expected no decomposition of an unknown stem, got %q", got)
+	}
+}
@@ -0,0 +1,156 @@
+package transliterator
+
+import (
+	"sort"
+	"strings"
+)
+
+// Characters that only shape the text and carry no letter of their own
+const (
+	tatweel         = 'ـ'
+	zeroWidthJoiner = '‍'
+	byteOrderMark   = '\uFEFF'
+)
+
+// hamzaCompositions gives the precomposed letter for a base letter followed by a
+// combining madda or hamza, as Unicode canonical composition (NFC) does
+var hamzaCompositions = map[rune]map[rune]rune{
+	madda:      {'ا': 'آ'},
+	hamzaAbove: {'ا': 'أ', 'و': 'ؤ', 'ي': 'ئ', 'ە': 'ۀ', 'ہ': 'ۂ', 'ے': 'ۓ'},
+	hamzaBelow: {'ا': 'إ'},
+}
+
+// matchFolds unifies the spelling variants dictionary lookups should not tell apart:
+// alef and hamza seats, and the Arabic and Persian forms of ya, kaf and heh
+var matchFolds = map[rune]rune{
+	'أ': 'ا', 'إ': 'ا', 'آ': 'ا', 'ٱ': 'ا',
+	'ؤ': 'ء', 'ئ': 'ء',
+	'ی': 'ي', 'ک': 'ك',
+	'ۀ': 'ه', 'ە': 'ه',
+}
+
+// scriptFolds writes Arabic-script digits and punctuation with their ASCII counterparts
+var scriptFolds = map[rune]rune{
+	'٠': '0', '١': '1', '٢': '2', '٣': '3', '٤': '4', '٥': '5', '٦': '6', '٧': '7', '٨': '8', '٩': '9',
+	'۰': '0', '۱': '1', '۲': '2', '۳': '3', '۴': '4', '۵': '5', '۶': '6', '۷': '7', '۸': '8', '۹': '9',
+	'،': ',', '؛': ';', '؟': '?', '۔': '.', '٪': '%', '٫': '.', '٬': ',',
+}
+
+// normalizeText prepares source text for transliteration: presentation forms are
+// folded to the basic Arabic letters, combining madda and hamza are composed with
+// their letter (ا + ٓ → آ), digits and punctuation are written in ASCII, and tatweel,
+// zero width joiners and byte order marks are removed. The zero width non-joiner is
+// kept, as it separates Persian word parts.
+func normalizeText(text string) string {
+	var result []rune
+	base := -1 // index in result of the letter combining marks attach to
+
+	add := func(r rune) {
+		if folded, ok := scriptFolds[r]; ok {
+			r = folded
+		}
+		switch {
+		case r == tatweel || r == zeroWidthJoiner || r == byteOrderMark:
+			return
+		case r == madda || r == hamzaAbove || r == hamzaBelow:
+			if base >= 0 {
+				if composed, ok := hamzaCompositions[r][result[base]]; ok {
+					result[base] = composed
+					return
+				}
+			}
+		case isHaraka(r) || r == daggerAlif:
+			// Short vowels, sukun and shadda do not block the composition
+		default:
+			base = len(result)
+		}
+		result = append(result, r)
+	}
+
+	for _, r := range text {
+		if isPresentationForm(r) {
+			for _, f := range foldPresentationForm(r) {
+				add(f)
+			}
+			continue
+		}
+		add(r)
+	}
+
+	return string(result)
+}
+
+// isHaraka reports whether r is one of the harakat, sukun or shadda
+func isHaraka(r rune) bool {
+	return r >= fathatan && r <= sukun
+}
+
+// matchKey folds the spelling variants of a diacritic-free word into a single key
+func matchKey(word string) string {
+	return strings.Map(func(r rune) rune {
+		if r == tatweel || r == zeroWidthJoiner || r == zeroWidthNonJoiner {
+			return -1
+		}
+		if folded, ok := matchFolds[r]; ok {
+			return folded
+		}
+		return r
+	}, word)
+}
+
+// buildMatchIndex maps the match keys of the common words and divine names of dict to
+// their dictionary spelling. Keys shared by different spellings (أن and إن) are
+// left out, since a variant spelling cannot tell which word is meant.
+func buildMatchIndex(dict *Dictionary) map[string]string {
+	var spellings []string
+	for word := range dict.CommonWords {
+		spellings = append(spellings, word)
+	}
+	for word := range dict.DivineNames {
+		spellings = append(spellings, word)
+	}
+	sort.Strings(spellings)
+
+	index := make(map[string]string, len(spellings))
+	ambiguous := make(map[string]bool)
+	for _, word := range spellings {
+		key := matchKey(word)
+		if existing, exists := index[key]; exists && existing != word {
+			ambiguous[key] = true
+		}
+		index[key] = word
+	}
+	for key := range ambiguous {
+		delete(index, key)
+	}
+
+	return index
+}
+
+// UnknownCharacters lists the Arabic-script characters of text, after normalization,
+// that the letter map of lang and the vowel marks do not cover. Transliterate drops
+// these characters from heuristic readings.
+func (t *Transliterator) UnknownCharacters(text string, lang Language) []rune {
+	letters := t.letterMap(lang)
+	seen := make(map[rune]bool)
+	var unknown []rune
+
+	for _, r := range normalizeText(t.applyQuranicMarks(text)) {
+		if seen[r] || !t.containsArabicScript(string(r)) {
+			continue
+		}
+		seen[r] = true
+		if _, known := letters[r]; known {
+			continue
+		}
+		if _, known := t.vowelMarks[r]; known {
+			continue
+		}
+		unknown = append(unknown, r)
+	}
+
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i] < unknown[j]
+	})
+	return unknown
+}
@@ -0,0 +1,52 @@
+package transliterator
+
+import "testing"
+
+func TestSpellingVariants(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	// Arabic and Persian letter forms and missing hamza seats find the same entry
+	tests := []struct {
+		input    string
+		expected string
+		lang     Language
+	}{
+		{"الهی", "Iláhí", Arabic},  // Persian ya
+		{"الهي", "Iláhí", Arabic},  // no hamza on the alef
+		{"ايام", "ayyám", Persian}, // Arabic ya
+		{"كه", "kih", Persian},     // Arabic kaf
+		{"إلـهي", "Iláhí", Arabic}, // tatweel
+	}
+
+	for _, tt := range tests {
+		if got := trans.Transliterate(tt.input, tt.lang); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Combining hamza and madda are composed with their letter, keeping the hamza
+	for input, expected := range map[string]string{
+		"\u0627\u0655لهي":       "إلهي",
+		"س\u0648\u0654ال":       "سؤال",
+		"\u0627\u0653خر":        "آخر",
+		"\u0627\u064e\u0654نتَ": "أَنتَ",
+		"مـحـمـد":               "محمد",
+		"می‌خواهم":              "می‌خواهم",
+	} {
+		if got := normalizeText(input); got != expected {
+			t.Errorf("normalizeText(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+
+	// Spellings that differ only in the hamza seat of ا are ambiguous and not folded
+	index := buildMatchIndex(&Dictionary{CommonWords: map[string]WordEntry{"أن": {}, "إن": {}, "إله": {}}})
+	if _, exists := index["ان"]; exists {
+		t.Errorf("buildMatchIndex kept the ambiguous key ان")
+	}
+	if spelling := index["اله"]; spelling != "إله" {
+		t.Errorf("buildMatchIndex[اله] = %q, expected إله", spelling)
+	}
+}
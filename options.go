@@ -0,0 +1,52 @@
+package transliterator
+
+import "io/fs"
+
+// Default locations of the dictionaries inside the dictionary filesystem
+const (
+	DefaultArabicDictionaryPath  = "data/arabic_dictionary.json"
+	DefaultPersianDictionaryPath = "data/persian_dictionary.json"
+)
+
+// Option configures a Transliterator created with NewWithOptions
+type Option func(*options)
+
+// options holds the settings collected from Option values
+type options struct {
+	dictionaryFS fs.FS
+	arabicPath   string
+	persianPath  string
+	layers       []Layer
+	irab         IrabPolicy
+	quranicMarks QuranicMarkPolicy
+}
+
+// defaultOptions returns the settings used by New: the embedded dictionaries
+func defaultOptions() options {
+	return options{
+		dictionaryFS: embeddedDictionaries,
+		arabicPath:   DefaultArabicDictionaryPath,
+		persianPath:  DefaultPersianDictionaryPath,
+	}
+}
+
+// WithDictionaryFS loads the dictionaries from fsys instead of the embedded copies.
+// Use os.DirFS to read them from a directory on disk.
+func WithDictionaryFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.dictionaryFS = fsys
+	}
+}
+
+// WithDictionaryPaths sets where the Arabic and Persian dictionaries live inside
+// the dictionary filesystem. An empty path keeps the default for that language.
+func WithDictionaryPaths(arabic, persian string) Option {
+	return func(o *options) {
+		if arabic != "" {
+			o.arabicPath = arabic
+		}
+		if persian != "" {
+			o.persianPath = persian
+		}
+	}
+}
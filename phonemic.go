@@ -0,0 +1,145 @@
+package transliterator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The phonemic field of a dictionary entry spells the word once for every scheme,
+// in the manner of ArabTeX:
+//
+//	consonants   b t j d r z s f q k l m n h w y, and p g v ^c (چ) ^z (ژ)
+//	digraphs     _t (ث) _h (خ) _d (ذ) ^s (ش) .g (غ)
+//	emphatics    .h (ح) .s (ص) .d (ض) .t (ط) .z (ظ)
+//	ʿayn, hamza  ` and '
+//	vowels       a i u e o, long A I U
+//	markers      * capitalizes what follows, | is the elided alif of the article
+//	             (illA|llAh), - and spaces are kept
+//
+// The hamza seat at the start of a word is not written, as in the Bahá'í scheme.
+
+// phonemicLetters gives the letter each consonant symbol stands for
+var phonemicLetters = map[string]rune{
+	"b": 'ب', "p": 'پ', "t": 'ت', "_t": 'ث', "j": 'ج', "^c": 'چ', ".h": 'ح', "_h": 'خ',
+	"d": 'د', "_d": 'ذ', "r": 'ر', "z": 'ز', "^z": 'ژ', "s": 'س', "^s": 'ش', ".s": 'ص',
+	".d": 'ض', ".t": 'ط', ".z": 'ظ', ".g": 'غ', "f": 'ف', "q": 'ق', "k": 'ك', "g": 'گ',
+	"l": 'ل', "m": 'م', "n": 'ن', "h": 'ه',
+}
+
+// phonemicLiterals are the symbols written the same in every scheme
+var phonemicLiterals = map[string]bool{
+	"a": true, "i": true, "u": true, "e": true, "o": true,
+	"w": true, "y": true, "v": true, "-": true, " ": true,
+}
+
+// RenderPhonemic writes the phonemic spelling of a dictionary entry in scheme
+func RenderPhonemic(phonemic string, lang Language, scheme Scheme) (string, error) {
+	letters := scheme.Letters(lang)
+	arabicLetters := scheme.Letters(Arabic)
+	long := scheme.LongVowels()
+	apostrophes := scheme.Apostrophes()
+
+	var result strings.Builder
+	capital := false
+	write := func(s string) {
+		if capital {
+			s = capitalize(s)
+			capital = false
+		}
+		result.WriteString(s)
+	}
+
+	for i := 0; i < len(phonemic); {
+		// Symbols are one character, or a mark (_ ^ .) and a letter
+		n := 1
+		if strings.ContainsRune("_^.", rune(phonemic[i])) && i+1 < len(phonemic) {
+			n = 2
+		}
+		symbol := phonemic[i : i+n]
+
+		switch {
+		case symbol == "*":
+			capital = true
+		case symbol == "`":
+			result.WriteString(apostrophes.Ayn)
+		case symbol == "'":
+			result.WriteString(apostrophes.Hamza)
+		case symbol == "|":
+			result.WriteString(apostrophes.Elision)
+		case symbol == "A":
+			write(long.A)
+		case symbol == "I":
+			write(long.I)
+		case symbol == "U":
+			write(long.U)
+		case phonemicLiterals[symbol]:
+			write(symbol)
+		default:
+			letter, ok := phonemicLetters[symbol]
+			if !ok {
+				return "", fmt.Errorf("unknown phonemic symbol %q in %q", symbol, phonemic)
+			}
+			trans, ok := letters[letter]
+			if !ok {
+				trans = arabicLetters[letter]
+			}
+			write(trans)
+		}
+		i += n
+	}
+
+	return result.String(), nil
+}
+
+// resolvePhonemic checks the phonemic spellings of the common words and divine names
+// of dict and gives entries without a transliteration their Bahá'í rendering. An
+// existing transliteration is kept, as it overrides the phonemic spelling.
+func resolvePhonemic(dict *Dictionary, lang Language) error {
+	for _, entries := range []map[string]WordEntry{dict.CommonWords, dict.DivineNames} {
+		words := make([]string, 0, len(entries))
+		for word := range entries {
+			words = append(words, word)
+		}
+		sort.Strings(words)
+
+		for _, word := range words {
+			entry := entries[word]
+			if entry.Phonemic == "" {
+				continue
+			}
+			trans, err := RenderPhonemic(entry.Phonemic, lang, SchemeBahai)
+			if err != nil {
+				return fmt.Errorf("entry %q: %v", word, err)
+			}
+			if entry.Transliteration == "" {
+				entry.Transliteration = trans
+				entries[word] = entry
+			}
+		}
+	}
+	return nil
+}
+
+// phonemicOutput renders the token in scheme from the phonemic spelling of its
+// dictionary entry, capitalized like the entry's transliteration. Tokens the
+// cross-word rules changed (anta'l-, raḥmatu'lláh) no longer match the entry and
+// are left to the scheme converter.
+func (t *Transliterator) phonemicOutput(tok token, lang Language, scheme Scheme) (string, bool) {
+	if tok.stage != StageCommonWord && tok.stage != StageDivineName {
+		return "", false
+	}
+
+	word, _ := splitTrailingPunctuation(tok.source)
+	entry, _, exists := t.Lookup(word, lang)
+	output, punct := splitTrailingPunctuation(tok.output)
+	if !exists || entry.Phonemic == "" || output != entry.Transliteration {
+		return "", false
+	}
+
+	rendered, err := RenderPhonemic(entry.Phonemic, lang, scheme)
+	if err != nil {
+		return "", false
+	}
+	return matchCase(rendered, entry.Transliteration) + punct, true
+}
@@ -0,0 +1,97 @@
+package transliterator
+
+import "testing"
+
+func TestRenderPhonemic(t *testing.T) {
+	tests := []struct {
+		phonemic string
+		lang     Language
+		scheme   Scheme
+		expected string
+	}{
+		{"*allAh", Arabic, SchemeBahai, "Alláh"},
+		{"*allAh", Arabic, SchemeDIN, "Allāh"},
+		{"^sifA'I", Arabic, SchemeALALC, "shifāʼī"},
+		{"^sifA'I", Arabic, SchemeDIN, "šifāʾī"},
+		{"al-*`AlamIn", Arabic, SchemeBahai, "al-'Álamín"},
+		{"al-*`AlamIn", Arabic, SchemeISO233, "al-ʿĀlamīn"},
+		{"illA|llAh", Arabic, SchemeDIN, "illāʾllāh"},
+		{"_tanA", Persian, SchemeALALC, "s̄anā"},
+		{"_tanA", Arabic, SchemeALALC, "thanā"},
+		{"ra.hmatuka", Arabic, SchemeASCII, "rahmatuka"},
+		{"*naw-*rUz", Persian, SchemeBahai, "Naw-Rúz"},
+	}
+
+	for _, tt := range tests {
+		result, err := RenderPhonemic(tt.phonemic, tt.lang, tt.scheme)
+		if err != nil {
+			t.Errorf("RenderPhonemic(%q, %s) failed: %v", tt.phonemic, tt.scheme.Name(), err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("RenderPhonemic(%q, %s) = %q, expected %q", tt.phonemic, tt.scheme.Name(), result, tt.expected)
+		}
+	}
+
+	if _, err := RenderPhonemic("ra7ma", Arabic, SchemeBahai); err == nil {
+		t.Errorf("RenderPhonemic should reject unknown symbols")
+	}
+}
+
+func TestPhonemicEntries(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	// The phonemic spellings of the dictionaries agree with their transliterations
+	for _, lang := range []Language{Arabic, Persian} {
+		dict := trans.dictionary(lang)
+		for _, entries := range []map[string]WordEntry{dict.CommonWords, dict.DivineNames} {
+			for word, entry := range entries {
+				if entry.Phonemic == "" {
+					continue
+				}
+				rendered, err := RenderPhonemic(entry.Phonemic, lang, SchemeBahai)
+				if err != nil || rendered != entry.Transliteration {
+					t.Errorf("%s: phonemic %q renders %q (%v), transliteration is %q", word, entry.Phonemic, rendered, err, entry.Transliteration)
+				}
+			}
+		}
+	}
+
+	layer := &Dictionary{
+		CommonWords: map[string]WordEntry{
+			"ذبيح": {Phonemic: "_dabI.h"},
+			"صبح":  {Transliteration: "Ṣubḥ", Phonemic: ".sub.h"},
+		},
+	}
+	trans, err = NewWithOptions(WithLayers(Layer{Name: "project", Language: Arabic, Dictionary: layer}))
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		scheme   Scheme
+		expected string
+	}{
+		{"ذبيح", SchemeBahai, "dhabíḥ"}, // rendered from the phonemic spelling
+		{"ذبيح", SchemeDIN, "ḏabīḥ"},
+		{"صبح", SchemeBahai, "Ṣubḥ"}, // the transliteration overrides the phonemic spelling
+		{"صبح", SchemeASCII, "Subh"}, // other schemes render the phonemic spelling
+		{"صبح", SchemeDIN, "Ṣubḥ"},   // in the case of the transliteration
+		{"صبح.", SchemeALALC, "Ṣubḥ."},
+		{"شفائي.", SchemeDIN, "šifāʾī."},
+	}
+	for _, tt := range tests {
+		if result := trans.TransliterateScheme(tt.input, Arabic, tt.scheme); result != tt.expected {
+			t.Errorf("TransliterateScheme(%q, %s) = %q, expected %q", tt.input, tt.scheme.Name(), result, tt.expected)
+		}
+	}
+
+	invalid := &Dictionary{CommonWords: map[string]WordEntry{"ذبيح": {Phonemic: "dhabih!"}}}
+	if _, err := NewWithOptions(WithLayers(Layer{Name: "project", Language: Arabic, Dictionary: invalid})); err == nil {
+		t.Errorf("NewWithOptions should reject an invalid phonemic spelling")
+	}
+}
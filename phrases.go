@@ -0,0 +1,101 @@
+package transliterator
+
+import (
+	"sort"
+	"strings"
+)
+
+// phrase is a common_phrases entry
+type phrase struct {
+	text            string // the phrase as written in the dictionary
+	transliteration string
+}
+
+// phraseNode is a node of the trie of dictionary phrases, keyed word by word on
+// the match keys of the words
+type phraseNode struct {
+	children map[string]*phraseNode
+	phrase   *phrase // the phrase ending at this node, if any
+}
+
+// buildPhraseTrie indexes the common phrases of a dictionary word by word. Phrases
+// whose words share match keys (spelling variants of one phrase) keep the first
+// spelling in sort order.
+func buildPhraseTrie(dict *Dictionary) *phraseNode {
+	texts := make([]string, 0, len(dict.CommonPhrases))
+	for text := range dict.CommonPhrases {
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+
+	root := &phraseNode{}
+	for _, text := range texts {
+		entry := dict.CommonPhrases[text]
+		words := strings.Fields(text)
+		if len(words) == 0 || entry.Transliteration == "" {
+			continue
+		}
+
+		node := root
+		for _, word := range words {
+			key := matchKey(word)
+			if node.children == nil {
+				node.children = make(map[string]*phraseNode)
+			}
+			child, exists := node.children[key]
+			if !exists {
+				child = &phraseNode{}
+				node.children[key] = child
+			}
+			node = child
+		}
+		if node.phrase == nil {
+			node.phrase = &phrase{text: text, transliteration: entry.Transliteration}
+		}
+	}
+
+	return root
+}
+
+// matchPhrase looks for the longest dictionary phrase at the start of words. It
+// returns the phrase as a single token and the number of words it covers, or 0 when
+// no phrase matches. Vowel marks and spelling variants are ignored, and a phrase does
+// not continue past trailing punctuation or a line break.
+func (t *Transliterator) matchPhrase(words []token, lang Language) (token, int) {
+	node := t.phrases[lang]
+	var found *phrase
+	n := 0
+	punct := ""
+
+	for i, word := range words {
+		clean, trailing := splitTrailingPunctuation(t.removeDiacritics(word.source))
+		next, exists := node.children[matchKey(clean)]
+		if !exists {
+			break
+		}
+		node = next
+		if node.phrase != nil {
+			found, n, punct = node.phrase, i+1, trailing
+		}
+		if trailing != "" || word.lineEnd {
+			break
+		}
+	}
+	if found == nil {
+		return token{}, 0
+	}
+
+	sources := make([]string, n)
+	for i := range sources {
+		sources[i] = words[i].source
+	}
+	return token{
+		source:  strings.Join(sources, " "),
+		output:  appendPunctuation(found.transliteration, punct),
+		start:   words[0].start,
+		end:     words[n-1].end,
+		stage:   StagePhrase,
+		layer:   t.layers[lang].commonPhrases[found.text],
+		lineEnd: words[n-1].lineEnd,
+	}, n
+}
@@ -0,0 +1,59 @@
+package transliterator
+
+import "testing"
+
+func TestPhraseMatching(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		phrase   bool // the first token is a phrase entry
+	}{
+		{"whole phrase", "لا إله إلا الله", "lá iláha illá'lláh", true},
+		{"vocalized phrase", "لَا إِلَهَ إِلَّا الله", "lá iláha illá'lláh", true},
+		{"longest phrase first", "أنت المهيمن القيوم.", "anta'l-Muhayminu'l-Qayyúm.", true},
+		{"prefix of a phrase only", "أنت المهيمن", "anta'l-Muhaymín", false},
+		{"phrase inside a longer word", "يا إلهيات", "yá ilahayát", false},
+		{"phrase after a clitic", "ويا إلهي", "wa-yá Iláhí", false},
+		{"phrase across a line break", "يا\nإلهي", "yá Iláhí", false},
+		{"phrase across punctuation", "يا، إلهي", "yá, Iláhí", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, alignments := trans.TransliterateAligned(tt.input, Arabic)
+			if output != tt.expected {
+				t.Errorf("Transliterate(%q) = %q, expected %q", tt.input, output, tt.expected)
+			}
+			if phrase := alignments[0].Stage == StagePhrase; phrase != tt.phrase {
+				t.Errorf("first token stage = %s, phrase expected: %v", alignments[0].Stage, tt.phrase)
+			}
+		})
+	}
+}
+
+func TestPhraseTrieSpellingVariants(t *testing.T) {
+	dict := &Dictionary{CommonPhrases: map[string]Pattern{
+		"إن الله":    {Transliteration: "inna'lláh"},
+		"ان الله":    {Transliteration: "variant"},
+		"إن الله لا": {Transliteration: "inna'lláha lá"},
+	}}
+	root := buildPhraseTrie(dict)
+
+	node := root.children[matchKey("إن")].children[matchKey("الله")]
+	if node == nil || node.phrase == nil {
+		t.Fatalf("phrase إن الله is missing from the trie")
+	}
+	// Both spellings share their match keys; the first in sort order is kept
+	if node.phrase.text != "إن الله" {
+		t.Errorf("phrase = %q, expected إن الله", node.phrase.text)
+	}
+	if next := node.children[matchKey("لا")]; next == nil || next.phrase == nil {
+		t.Errorf("longer phrase إن الله لا is missing from the trie")
+	}
+}
@@ -0,0 +1,32 @@
+package transliterator
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// presentationExceptions are the presentation forms NFKC leaves alone: the ornate
+// parentheses, written the way round they read in the Latin text, and the bismillah
+// ligature, which is spelled out
+var presentationExceptions = map[rune]string{
+	'﴾': ")",                      // ornate left parenthesis
+	'﴿': "(",                      // ornate right parenthesis
+	'﷽': "بسم الله الرحمن الرحيم", // ligature bismillah ar-rahman ar-raheem
+}
+
+// isPresentationForm reports whether r is in the Arabic Presentation Forms-A or -B
+// (U+FB50–U+FDFF, U+FE70–U+FEFF)
+func isPresentationForm(r rune) bool {
+	return (r >= 0xFB50 && r <= 0xFDFF) || (r >= 0xFE70 && r <= 0xFEFF)
+}
+
+// foldPresentationForm folds a presentation form to the letters of the basic Arabic
+// block through its NFKC compatibility decomposition; ligatures become their letter
+// sequence. Isolated and medial harakat lose the space or tatweel NFKC writes them on.
+func foldPresentationForm(r rune) string {
+	if folded, ok := presentationExceptions[r]; ok {
+		return folded
+	}
+	return strings.TrimLeft(norm.NFKC.String(string(r)), " "+string(tatweel))
+}
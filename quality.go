@@ -0,0 +1,85 @@
+package transliterator
+
+import "fmt"
+
+// Quality sums up how a transliteration was produced, to decide whether it can be
+// written back without a manual check
+type Quality struct {
+	Tokens          int     // tokens in Arabic script
+	DictionaryShare float64 // share of those tokens resolved by a dictionary entry
+	VocalizedShare  float64 // share of those tokens read from the vowel marks of the source
+	Confidence      float64 // mean confidence of those tokens
+	Leaked          []rune  // Arabic-script codepoints left in the output
+}
+
+// ResolvedShare is the share of tokens resolved by a dictionary entry or by the vowel
+// marks of the source
+func (q Quality) ResolvedShare() float64 {
+	return q.DictionaryShare + q.VocalizedShare
+}
+
+// Quality measures the result. Tokens copied unchanged, such as Latin text and
+// numbers, are left out of the shares.
+func (r Result) Quality() Quality {
+	q := Quality{Leaked: r.Leaked}
+
+	dictionary, vocalized := 0, 0
+	confidence := 0.0
+	for _, tok := range r.Tokens {
+		if tok.Stage == StagePassthrough {
+			continue
+		}
+		q.Tokens++
+		confidence += tok.Confidence
+		switch tok.Stage {
+		case StagePhrase, StageCommonWord, StageDivineName:
+			dictionary++
+		case StageVocalized:
+			vocalized++
+		}
+	}
+
+	if q.Tokens > 0 {
+		q.DictionaryShare = float64(dictionary) / float64(q.Tokens)
+		q.VocalizedShare = float64(vocalized) / float64(q.Tokens)
+		q.Confidence = confidence / float64(q.Tokens)
+	}
+	return q
+}
+
+// QualityGate holds the thresholds a transliteration must pass
+type QualityGate struct {
+	MinResolvedShare float64 // minimum share of tokens resolved by a dictionary entry or by vowel marks
+	MinConfidence    float64 // minimum mean confidence of the tokens
+	AllowLeaked      bool    // accept Arabic-script characters left in the output
+}
+
+// DefaultQualityGate rejects output with leaked Arabic script, with fewer than a
+// quarter of its tokens resolved by the dictionaries or the vowel marks of the source,
+// or with a mean confidence below 0.5
+func DefaultQualityGate() QualityGate {
+	return QualityGate{
+		MinResolvedShare: 0.25,
+		MinConfidence:    0.5,
+	}
+}
+
+// Check returns the reasons q fails the gate, or nil if it passes. A result without
+// Arabic-script tokens has nothing to measure and always fails.
+func (g QualityGate) Check(q Quality) []string {
+	if q.Tokens == 0 {
+		return []string{"no Arabic-script text to check"}
+	}
+
+	var reasons []string
+	if !g.AllowLeaked && len(q.Leaked) > 0 {
+		reasons = append(reasons, fmt.Sprintf("Arabic-script characters left in the output: %q", string(q.Leaked)))
+	}
+	if resolved := q.ResolvedShare(); resolved < g.MinResolvedShare {
+		reasons = append(reasons, fmt.Sprintf("resolved share %.2f below %.2f", resolved, g.MinResolvedShare))
+	}
+	if q.Confidence < g.MinConfidence {
+		reasons = append(reasons, fmt.Sprintf("confidence %.2f below %.2f", q.Confidence, g.MinConfidence))
+	}
+	return reasons
+}
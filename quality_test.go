@@ -0,0 +1,80 @@
+package transliterator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestResultQuality(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	result, err := trans.TransliterateWithReport("يا إلهي اسمك ڭبد Bahá'í", Arabic)
+	if err != nil {
+		t.Fatalf("TransliterateWithReport failed: %v", err)
+	}
+	q := result.Quality()
+
+	// The phrase and اسمك come from the dictionary, ڭبد is a heuristic reading and the
+	// Latin word is left out
+	if q.Tokens != 3 {
+		t.Fatalf("Tokens = %d, expected 3: %+v", q.Tokens, result.Tokens)
+	}
+	if math.Abs(q.DictionaryShare-2.0/3) > 1e-9 || q.VocalizedShare != 0 {
+		t.Errorf("DictionaryShare = %v, VocalizedShare = %v, expected 2/3 and 0", q.DictionaryShare, q.VocalizedShare)
+	}
+	if q.Confidence <= stageConfidence[StageHeuristic] || q.Confidence >= 1 {
+		t.Errorf("Confidence = %v, expected a mean of the token confidences", q.Confidence)
+	}
+	if len(q.Leaked) != 0 {
+		t.Errorf("Leaked = %q, expected none", string(q.Leaked))
+	}
+
+	vocalized, _ := trans.TransliterateWithReport("كَتَبَ", Arabic)
+	if q := vocalized.Quality(); q.VocalizedShare != 1 {
+		t.Errorf("VocalizedShare = %v, expected 1", q.VocalizedShare)
+	}
+
+	if q := (Result{}).Quality(); q.Tokens != 0 || q.Confidence != 0 {
+		t.Errorf("empty result quality = %+v", q)
+	}
+}
+
+func TestQualityGate(t *testing.T) {
+	gate := DefaultQualityGate()
+
+	tests := []struct {
+		name    string
+		quality Quality
+		reasons int
+	}{
+		{"passes", Quality{Tokens: 4, DictionaryShare: 0.5, Confidence: 0.8}, 0},
+		{"vowel marks count as resolved", Quality{Tokens: 4, DictionaryShare: 0.1, VocalizedShare: 0.5, Confidence: 0.9}, 0},
+		{"few resolved tokens", Quality{Tokens: 4, DictionaryShare: 0.1, VocalizedShare: 0.1, Confidence: 0.8}, 1},
+		{"low confidence", Quality{Tokens: 4, DictionaryShare: 0.5, Confidence: 0.4}, 1},
+		{"leaked script", Quality{Tokens: 4, DictionaryShare: 0.5, Confidence: 0.8, Leaked: []rune{'ڭ'}}, 1},
+		{"everything fails", Quality{Tokens: 4, Confidence: 0.3, Leaked: []rune{'ڭ'}}, 3},
+		{"no Arabic-script tokens", Quality{}, 1},
+	}
+
+	for _, tt := range tests {
+		if reasons := gate.Check(tt.quality); len(reasons) != tt.reasons {
+			t.Errorf("%s: Check = %q, expected %d reasons", tt.name, reasons, tt.reasons)
+		}
+	}
+
+	if reasons := gate.Check(Quality{Tokens: 4, DictionaryShare: 0.1, VocalizedShare: 0.1, Confidence: 0.8}); len(reasons) != 1 || reasons[0] != "resolved share 0.20 below 0.25" {
+		t.Errorf("Check = %q, expected the resolved share below the minimum", reasons)
+	}
+	if reasons := gate.Check(Quality{}); len(reasons) != 1 || strings.Contains(reasons[0], "confidence") {
+		t.Errorf("empty quality: Check = %q, expected only the missing Arabic-script text", reasons)
+	}
+
+	gate.AllowLeaked = true
+	if reasons := gate.Check(Quality{Tokens: 1, DictionaryShare: 1, Confidence: 1, Leaked: []rune{'ڭ'}}); len(reasons) != 0 {
+		t.Errorf("AllowLeaked: Check = %q, expected none", reasons)
+	}
+}
@@ -0,0 +1,89 @@
+package transliterator
+
+import "unicode"
+
+// QuranicMarkPolicy decides what happens to the pause and annotation marks of
+// Quranic text (U+06D6–U+06ED)
+type QuranicMarkPolicy int
+
+const (
+	// QuranicMarksRemove drops the pause and annotation marks
+	QuranicMarksRemove QuranicMarkPolicy = iota
+	// QuranicMarksPunctuation writes pause marks as punctuation: ayah ends become a
+	// full stop, the compulsory stop a semicolon and the other pauses a comma
+	QuranicMarksPunctuation
+)
+
+// String returns the name of the policy
+func (p QuranicMarkPolicy) String() string {
+	switch p {
+	case QuranicMarksRemove:
+		return "remove"
+	case QuranicMarksPunctuation:
+		return "punctuation"
+	}
+	return "unknown"
+}
+
+// WithQuranicMarks sets how Quranic pause marks are treated; the default is QuranicMarksRemove
+func WithQuranicMarks(policy QuranicMarkPolicy) Option {
+	return func(o *options) {
+		o.quranicMarks = policy
+	}
+}
+
+// quranicPauses gives the punctuation of each Quranic pause mark
+var quranicPauses = map[rune]string{
+	'ۖ': ",", // small high ligature sad with lam with alef maksura: pausing preferred
+	'ۗ': ",", // small high ligature qaf with lam with alef maksura: stopping preferred
+	'ۘ': ";", // small high meem initial form: compulsory stop
+	'ۚ': ",", // small high jeem: stopping permitted
+	'ۛ': ",", // small high three dots: stop at one of two places
+	'۝': ".", // end of ayah
+	'۪': ",", // empty centre low stop
+	'۫': ",", // empty centre high stop
+	'۬': ",", // rounded high stop with filled centre
+}
+
+// quranicLetters writes the Quranic marks that stand for a letter or a haraka
+// with the ordinary character
+var quranicLetters = map[rune]rune{
+	'ۡ': sukun, // small high dotless head of khah
+	'ۤ': madda, // small high madda
+	'ۥ': 'و',   // small waw, the long vowel of the pronoun (هُۥ)
+	'ۦ': 'ي',   // small yeh
+}
+
+// isQuranicMark reports whether r is in the Quranic annotation range U+06D6–U+06ED
+func isQuranicMark(r rune) bool {
+	return r >= 0x06D6 && r <= 0x06ED
+}
+
+// applyQuranicMarks rewrites the Quranic annotation marks of text: letter-like marks
+// become their letters, pause marks punctuation or nothing according to the policy,
+// and the remaining annotations (sajdah, rub el hizb, small high letters) are removed.
+// A pause mark written apart from its word is attached to the word before it.
+func (t *Transliterator) applyQuranicMarks(text string) string {
+	var result []rune
+	for _, r := range text {
+		if !isQuranicMark(r) {
+			result = append(result, r)
+			continue
+		}
+		if letter, ok := quranicLetters[r]; ok {
+			result = append(result, letter)
+			continue
+		}
+		if punct, ok := quranicPauses[r]; ok && t.quranicMarks == QuranicMarksPunctuation {
+			for len(result) > 0 && unicode.IsSpace(result[len(result)-1]) {
+				result = result[:len(result)-1]
+			}
+			result = append(result, []rune(punct)...)
+			// The ayah number that may follow the end of ayah mark stays apart
+			if r == '۝' {
+				result = append(result, ' ')
+			}
+		}
+	}
+	return string(result)
+}
@@ -0,0 +1,95 @@
+package transliterator
+
+import "testing"
+
+func TestQuranicMarks(t *testing.T) {
+	const verse = "ذَٰلِكَ ٱلْكِتَٰبُ لَا رَيْبَ ۛ فِيهِ ۛ هُدًى لِّلْمُتَّقِينَ ۝٢"
+
+	tests := []struct {
+		policy   QuranicMarkPolicy
+		expected string
+	}{
+		{QuranicMarksRemove, "dhálika'l-kitábu lá rayba fíhi hudan li'l-muttaqína 2"},
+		{QuranicMarksPunctuation, "dhálika'l-kitábu lá rayba, fíhi, hudan li'l-muttaqína. 2"},
+	}
+
+	for _, tt := range tests {
+		trans, err := NewWithOptions(WithQuranicMarks(tt.policy))
+		if err != nil {
+			t.Fatalf("Failed to create transliterator: %v", err)
+		}
+		if got := trans.Transliterate(verse, Arabic); got != tt.expected {
+			t.Errorf("%v: Transliterate = %q, expected %q", tt.policy, got, tt.expected)
+		}
+	}
+
+	// Marks standing for a letter or a haraka are read as such
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	for input, expected := range map[string]string{
+		"لَهُۥ":     "لَهُو",
+		"قَالُوا۟":  "قَالُوا",
+		"عَلَيْهِۦ": "عَلَيْهِي",
+		"مِنۡ":      "مِنْ",
+	} {
+		if got := trans.applyQuranicMarks(input); got != expected {
+			t.Errorf("applyQuranicMarks(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestPresentationForms(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	// Text copied from PDFs: contextual letter forms and ligatures
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ﻻ ﺇﻟﻪ ﺇﻻ ﷲ", "lá iláha illá'lláh"},
+		{"ﺍﻟﺤﻤﺪ", "al-ḥamdu"},
+		{"ﺇﻟﻬﻲ", "Iláhí"},
+	}
+
+	for _, tt := range tests {
+		if got := trans.Transliterate(tt.input, Arabic); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// NFKC, except for the ornate parentheses, the bismillah and the carrier of
+	// isolated and medial harakat
+	for input, expected := range map[string]string{
+		"\uFEFFﷲ ﴿ﺑﺴﻢ﴾ ١٢": "الله (بسم) 12",
+		"﷽":                "بسم الله الرحمن الرحيم",
+		"ﻛ\uFE77ﺘ\uFE7Cﺐ":  "كَتّب",
+		"ﺷﻜﺮا\uFE70":       "شكراً",
+		"\uFC60":           "َّ",
+	} {
+		if got := normalizeText(input); got != expected {
+			t.Errorf("normalizeText(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestUnknownCharacters(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	if unknown := trans.UnknownCharacters("ﺍﻟﺤﻤﺪ لله ۞ ٱلْكِتَٰبُ", Arabic); len(unknown) != 0 {
+		t.Errorf("UnknownCharacters reported %q for fully covered text", string(unknown))
+	}
+
+	// Letters of other languages written in Arabic script are reported once each
+	unknown := trans.UnknownCharacters("ڭڭ ۋ کتاب", Arabic)
+	if string(unknown) != "ڭۋ" {
+		t.Errorf("UnknownCharacters = %q, expected %q", string(unknown), "ڭۋ")
+	}
+}
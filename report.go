@@ -0,0 +1,132 @@
+package transliterator
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Stage names the step of the pipeline that produced the transliteration of a token
+type Stage string
+
+const (
+	StagePhrase      Stage = "phrase"      // a common_phrases entry
+	StageCommonWord  Stage = "common_word" // a common_words entry
+	StageDivineName  Stage = "divine_name" // a divine_names entry
+	StageVocalized   Stage = "vocalized"   // read from the vowel marks of the source
+	StageArticle     Stage = "article"     // definite article analysis (al-, wa'l-)
+	StageCompound    Stage = "compound"    // compound word analysis
+	StageMorphology  Stage = "morphology"  // clitic or suffix segmentation
+	StageHeuristic   Stage = "heuristic"   // letter-by-letter fallback
+	StagePassthrough Stage = "passthrough" // not in Arabic script, copied unchanged
+)
+
+// stageConfidence is the confidence of a token transliterated by each stage
+var stageConfidence = map[Stage]float64{
+	StagePhrase:      1.0,
+	StageCommonWord:  1.0,
+	StageDivineName:  1.0,
+	StagePassthrough: 1.0,
+	StageVocalized:   0.9,
+	StageArticle:     0.8,
+	StageMorphology:  0.7,
+	StageCompound:    0.6,
+	StageHeuristic:   0.3,
+}
+
+// Result is the transliteration of a text together with a report on each token
+type Result struct {
+	Text    string        // the transliteration, as returned by Transliterate
+	Tokens  []TokenReport // the tokens of the source, in order
+	Dropped []rune        // codepoints dropped anywhere in the text, sorted
+	Leaked  []rune        // Arabic-script codepoints left in Text, sorted
+}
+
+// TokenReport describes how one token of the source was transliterated
+type TokenReport struct {
+	Source     string  // the token as written in the source text
+	Start      int     // byte offset of Source in the source text
+	End        int     // byte offset just past Source in the source text
+	Output     string  // transliteration of the token, before the final formatting
+	Stage      Stage   // pipeline step that produced Output
+	Layer      string  // dictionary layer of the entry, for dictionary stages
+	Confidence float64 // between 0 and 1; tokens below 0.5 deserve a manual check
+	Dropped    []rune  // codepoints of Source the transliteration leaves out
+}
+
+// TransliterateWithReport transliterates text like Transliterate and reports, for every
+// token, where it is in text, which stage produced it, how confident that stage is and
+// which characters it could not transliterate
+func (t *Transliterator) TransliterateWithReport(text string, lang Language) (Result, error) {
+	if lang != Arabic && lang != Persian {
+		return Result{}, fmt.Errorf("unknown language %d", lang)
+	}
+	if !utf8.ValidString(text) {
+		return Result{}, fmt.Errorf("text is not valid UTF-8")
+	}
+
+	tokens := t.transliterateTokens(text, lang)
+	output, _ := t.render(tokens)
+	result := Result{Text: output}
+
+	dropped := make(map[rune]bool)
+	for _, tok := range tokens {
+		report := TokenReport{
+			Source:     text[tok.start:tok.end],
+			Start:      tok.start,
+			End:        tok.end,
+			Output:     tok.output,
+			Stage:      tok.stage,
+			Confidence: stageConfidence[tok.stage],
+		}
+
+		switch tok.stage {
+		case StageCommonWord, StageDivineName:
+			word, _ := splitTrailingPunctuation(tok.source)
+			_, report.Layer, _ = t.Lookup(word, lang)
+		case StagePhrase:
+			report.Layer = tok.layer
+		default:
+			report.Dropped = t.UnknownCharacters(tok.source, lang)
+		}
+		if len(report.Dropped) > 0 {
+			report.Confidence /= 2
+		}
+		for _, r := range report.Dropped {
+			dropped[r] = true
+		}
+
+		result.Tokens = append(result.Tokens, report)
+	}
+
+	for r := range dropped {
+		result.Dropped = append(result.Dropped, r)
+	}
+	sort.Slice(result.Dropped, func(i, j int) bool {
+		return result.Dropped[i] < result.Dropped[j]
+	})
+
+	leaked := make(map[rune]bool)
+	for _, r := range output {
+		if !leaked[r] && t.containsArabicScript(string(r)) {
+			leaked[r] = true
+			result.Leaked = append(result.Leaked, r)
+		}
+	}
+	sort.Slice(result.Leaked, func(i, j int) bool {
+		return result.Leaked[i] < result.Leaked[j]
+	})
+
+	return result, nil
+}
+
+// NeedsReview returns the tokens whose confidence is below threshold
+func (r Result) NeedsReview(threshold float64) []TokenReport {
+	var review []TokenReport
+	for _, tok := range r.Tokens {
+		if tok.Confidence < threshold {
+			review = append(review, tok)
+		}
+	}
+	return review
+}
@@ -0,0 +1,67 @@
+package transliterator
+
+import "testing"
+
+func TestTransliterateWithReport(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	text := "يا إلهي اسمك وبذكرك\nالمهيمن بالاسم ڭبد ۞"
+	result, err := trans.TransliterateWithReport(text, Arabic)
+	if err != nil {
+		t.Fatalf("TransliterateWithReport failed: %v", err)
+	}
+
+	if result.Text != trans.Transliterate(text, Arabic) {
+		t.Errorf("Text = %q, expected the Transliterate output %q", result.Text, trans.Transliterate(text, Arabic))
+	}
+
+	expected := []struct {
+		source string
+		stage  Stage
+	}{
+		{"يا إلهي", StagePhrase},
+		{"اسمك", StageCommonWord},
+		{"وبذكرك", StageMorphology},
+		{"المهيمن", StageDivineName},
+		{"بالاسم", StageArticle},
+		{"ڭبد", StageHeuristic},
+	}
+	if len(result.Tokens) != len(expected) {
+		t.Fatalf("got %d tokens, expected %d: %+v", len(result.Tokens), len(expected), result.Tokens)
+	}
+	for i, want := range expected {
+		tok := result.Tokens[i]
+		if tok.Source != want.source || tok.Stage != want.stage {
+			t.Errorf("token %d = %q (%s), expected %q (%s)", i, tok.Source, tok.Stage, want.source, want.stage)
+		}
+		if text[tok.Start:tok.End] != tok.Source {
+			t.Errorf("token %d span %d:%d does not cover %q", i, tok.Start, tok.End, tok.Source)
+		}
+	}
+
+	if layer := result.Tokens[0].Layer; layer != BaseLayer {
+		t.Errorf("phrase layer = %q, expected %q", layer, BaseLayer)
+	}
+	if layer := result.Tokens[3].Layer; layer != BaseLayer {
+		t.Errorf("divine name layer = %q, expected %q", layer, BaseLayer)
+	}
+
+	// The unmapped letter is reported and lowers the confidence of its token
+	if string(result.Dropped) != "ڭ" || string(result.Tokens[5].Dropped) != "ڭ" {
+		t.Errorf("Dropped = %q, token dropped = %q, expected ڭ", string(result.Dropped), string(result.Tokens[5].Dropped))
+	}
+	review := result.NeedsReview(0.5)
+	if len(review) != 1 || review[0].Source != "ڭبد" {
+		t.Errorf("NeedsReview(0.5) = %+v, expected only ڭبد", review)
+	}
+
+	if _, err := trans.TransliterateWithReport(text, Language(7)); err == nil {
+		t.Errorf("expected an error for an unknown language")
+	}
+	if _, err := trans.TransliterateWithReport("\xff", Arabic); err == nil {
+		t.Errorf("expected an error for invalid UTF-8")
+	}
+}
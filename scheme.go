@@ -0,0 +1,472 @@
+package transliterator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scheme is a romanization scheme: the letters, long vowels and apostrophes a
+// transliteration is written with. The dictionaries are written in the Bahá'í
+// scheme; in other schemes, words read letter by letter use the letters of the
+// scheme and dictionary words are rewritten token by token.
+type Scheme interface {
+	// Name identifies the scheme, as accepted by SchemeByName
+	Name() string
+	// Letters gives the romanization of each letter of lang
+	Letters(lang Language) map[rune]string
+	// LongVowels gives the signs for the long vowels
+	LongVowels() LongVowels
+	// Apostrophes gives the signs for ʿayn, hamza and the elided article
+	Apostrophes() Apostrophes
+}
+
+// LongVowels are the long vowels of a scheme (á or ā)
+type LongVowels struct {
+	A, I, U string
+}
+
+// Apostrophes are the signs a scheme writes for ʿayn, hamza and the elided alif of
+// the article (the apostrophe of anta'l-)
+type Apostrophes struct {
+	Ayn, Hamza, Elision string
+}
+
+// romanization is a Scheme defined by the letters it writes differently from the
+// Bahá'í scheme
+type romanization struct {
+	name        string
+	letters     map[Language]map[rune]string // letters that differ from the Bahá'í scheme
+	longVowels  LongVowels
+	apostrophes Apostrophes
+}
+
+// bahaiLetters is the Bahá'í romanization of the letters of each language
+var bahaiLetters = map[Language]map[rune]string{
+	Arabic: {
+		'ا': "á", 'أ': "a", 'إ': "i", 'آ': "á", 'ب': "b", 'ت': "t", 'ث': "th", 'ج': "j", 'ح': "ḥ", 'خ': "kh",
+		'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s", 'ش': "sh", 'ص': "ṣ",
+		'ض': "ḍ", 'ط': "ṭ", 'ظ': "ẓ", 'ع': "'", 'غ': "gh", 'ف': "f", 'ق': "q",
+		'ك': "k", 'ک': "k", 'ل': "l", 'م': "m", 'ن': "n", 'ه': "h", 'و': "w", 'ي': "y",
+		'ى': "á", 'ة': "h", 'ء': "'", 'ؤ': "u'", 'ئ': "i'", 'ی': "y", 'ٱ': "a",
+		'پ': "p", 'چ': "ch", 'ژ': "zh", 'گ': "g", 'ڤ': "v",
+	},
+	Persian: {
+		'ا': "á", 'ب': "b", 'پ': "p", 'ت': "t", 'ث': "th", 'ج': "j", 'چ': "ch",
+		'ح': "ḥ", 'خ': "kh", 'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'ژ': "zh",
+		'س': "s", 'ش': "sh", 'ص': "ṣ", 'ض': "ḍ", 'ط': "ṭ", 'ظ': "ẓ", 'ع': "'",
+		'غ': "gh", 'ف': "f", 'ق': "q", 'ک': "k", 'گ': "g", 'ل': "l", 'م': "m",
+		'ن': "n", 'و': "v", 'ه': "h", 'ی': "í", 'ى': "á", 'ة': "h", 'ء': "'",
+		'ك': "k", 'ي': "í", 'أ': "a", 'إ': "i", 'آ': "á", 'ٱ': "a", 'ؤ': "u'", 'ئ': "'",
+	},
+}
+
+// The romanization schemes the transliterator can write
+var (
+	// SchemeBahai is the Bahá'í system of the published writings (Bahá'u'lláh)
+	SchemeBahai Scheme = &romanization{
+		name:        "bahai",
+		longVowels:  LongVowels{A: "á", I: "í", U: "ú"},
+		apostrophes: Apostrophes{Ayn: "'", Hamza: "'", Elision: "'"},
+	}
+
+	// SchemeALALC follows the ALA-LC romanization tables of library catalogs
+	SchemeALALC Scheme = &romanization{
+		name: "ala-lc",
+		letters: map[Language]map[rune]string{
+			Persian: {'ث': "s̄", 'ذ': "ẕ", 'ض': "ż"},
+		},
+		longVowels:  LongVowels{A: "ā", I: "ī", U: "ū"},
+		apostrophes: Apostrophes{Ayn: "ʻ", Hamza: "ʼ", Elision: "ʼ"},
+	}
+
+	// SchemeDIN follows DIN 31635, extended with č and ž for Persian
+	SchemeDIN Scheme = &romanization{
+		name: "din",
+		letters: map[Language]map[rune]string{
+			Arabic:  {'ث': "ṯ", 'ج': "ǧ", 'خ': "ḫ", 'ذ': "ḏ", 'ش': "š", 'غ': "ġ", 'چ': "č", 'ژ': "ž"},
+			Persian: {'ث': "ṯ", 'ج': "ǧ", 'خ': "ḫ", 'ذ': "ḏ", 'ش': "š", 'غ': "ġ", 'چ': "č", 'ژ': "ž"},
+		},
+		longVowels:  LongVowels{A: "ā", I: "ī", U: "ū"},
+		apostrophes: Apostrophes{Ayn: "ʿ", Hamza: "ʾ", Elision: "ʾ"},
+	}
+
+	// SchemeISO233 follows ISO 233 for Arabic and ISO 233-3 for Persian
+	SchemeISO233 Scheme = &romanization{
+		name: "iso233",
+		letters: map[Language]map[rune]string{
+			Arabic:  {'ث': "ṯ", 'ج': "ǧ", 'خ': "ẖ", 'ذ': "ḏ", 'ش': "š", 'غ': "ġ", 'چ': "č", 'ژ': "ž"},
+			Persian: {'ث': "s̄", 'خ': "x", 'ذ': "ẕ", 'ض': "ż", 'ش': "š", 'غ': "ğ", 'چ': "č", 'ژ': "ž"},
+		},
+		longVowels:  LongVowels{A: "ā", I: "ī", U: "ū"},
+		apostrophes: Apostrophes{Ayn: "ʿ", Hamza: "ʾ", Elision: "ʾ"},
+	}
+
+	// SchemeASCII writes plain ASCII, for search keys and systems without Unicode
+	SchemeASCII Scheme = &romanization{
+		name: "ascii",
+		letters: map[Language]map[rune]string{
+			Arabic:  {'ح': "h", 'ص': "s", 'ض': "d", 'ط': "t", 'ظ': "z"},
+			Persian: {'ح': "h", 'ص': "s", 'ض': "d", 'ط': "t", 'ظ': "z"},
+		},
+		longVowels:  LongVowels{A: "a", I: "i", U: "u"},
+		apostrophes: Apostrophes{Ayn: "'", Hamza: "'", Elision: "'"},
+	}
+)
+
+// schemes lists the schemes by name
+var schemes = map[string]Scheme{
+	SchemeBahai.Name():  SchemeBahai,
+	SchemeALALC.Name():  SchemeALALC,
+	SchemeDIN.Name():    SchemeDIN,
+	SchemeISO233.Name(): SchemeISO233,
+	SchemeASCII.Name():  SchemeASCII,
+}
+
+// SchemeByName returns the scheme called name (bahai, ala-lc, din, iso233 or ascii)
+func SchemeByName(name string) (Scheme, error) {
+	scheme, ok := schemes[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown romanization scheme %q", name)
+	}
+	return scheme, nil
+}
+
+// Name returns the name of the scheme
+func (s *romanization) Name() string {
+	return s.name
+}
+
+// Letters returns the Bahá'í letters of lang with the long vowels, apostrophes and
+// letters of the scheme
+func (s *romanization) Letters(lang Language) map[rune]string {
+	letters := make(map[rune]string, len(bahaiLetters[lang]))
+	for r, trans := range bahaiLetters[lang] {
+		if override, ok := s.letters[lang][r]; ok {
+			letters[r] = override
+			continue
+		}
+		apostrophe := s.apostrophes.Hamza
+		if r == 'ع' {
+			apostrophe = s.apostrophes.Ayn
+		}
+		letters[r] = strings.NewReplacer(
+			"á", s.longVowels.A, "í", s.longVowels.I, "ú", s.longVowels.U, "'", apostrophe,
+		).Replace(trans)
+	}
+	return letters
+}
+
+// LongVowels returns the long vowels of the scheme
+func (s *romanization) LongVowels() LongVowels {
+	return s.longVowels
+}
+
+// Apostrophes returns the apostrophes of the scheme
+func (s *romanization) Apostrophes() Apostrophes {
+	return s.apostrophes
+}
+
+// TransliterateScheme transliterates text like Transliterate and writes the result
+// in scheme instead of the Bahá'í scheme
+func (t *Transliterator) TransliterateScheme(text string, lang Language, scheme Scheme) string {
+	tokens := t.transliterateTokens(text, lang)
+	tokens = t.applyScheme(tokens, lang, scheme)
+	output, _ := t.render(tokens)
+	return output
+}
+
+// applyScheme writes every token in scheme. Words read letter by letter are read again
+// with the letters of the scheme and dictionary words with a phonemic spelling are
+// rendered from it; the scheme converter rewrites the Bahá'í transliteration of the
+// others.
+func (t *Transliterator) applyScheme(tokens []token, lang Language, scheme Scheme) []token {
+	if scheme == nil || scheme == SchemeBahai {
+		return tokens
+	}
+
+	letters := t.inScheme(scheme)
+	converter := newSchemeConverter(scheme, lang)
+	for i := range tokens {
+		if tokens[i].stage == StagePassthrough {
+			continue
+		}
+		if output, ok := t.letterOutput(tokens[i], lang, letters); ok {
+			tokens[i].output = output
+			continue
+		}
+		if output, ok := t.phonemicOutput(tokens[i], lang, scheme); ok {
+			tokens[i].output = output
+			continue
+		}
+		tokens[i].output = converter.convert(tokens[i].source, tokens[i].output)
+	}
+	return tokens
+}
+
+// letterOutput reads a token of the letter-by-letter fallback again with letters, a
+// copy of t in another scheme. Tokens the cross-word rules changed (an ezafe, a pausal
+// ending) no longer match the reading and are left to the scheme converter.
+func (t *Transliterator) letterOutput(tok token, lang Language, letters *Transliterator) (string, bool) {
+	if tok.stage != StageHeuristic {
+		return "", false
+	}
+
+	word, punct := splitTrailingPunctuation(tok.source)
+	word, _ = t.splitEzafeMark(word, lang)
+	if output, _ := t.transliterateWord(word, lang); appendPunctuation(output, punct) != tok.output {
+		return "", false
+	}
+
+	output, _ := letters.transliterateWord(word, lang)
+	return appendPunctuation(output, punct), true
+}
+
+// consonantRule rewrites the Bahá'í romanization of a consonant letter
+type consonantRule struct {
+	letter rune
+	from   []rune // the Bahá'í romanization
+	to     string // the romanization in the scheme
+}
+
+// schemeConverter rewrites Bahá'í transliterations in another scheme
+type schemeConverter struct {
+	bahai       map[rune]string
+	consonants  []consonantRule // longest romanization first
+	longVowels  map[rune]string
+	apostrophes Apostrophes
+}
+
+// newSchemeConverter pairs the Bahá'í and the scheme romanization of every consonant
+// of lang
+func newSchemeConverter(scheme Scheme, lang Language) *schemeConverter {
+	long := scheme.LongVowels()
+	c := &schemeConverter{
+		bahai:       bahaiLetters[lang],
+		longVowels:  map[rune]string{'á': long.A, 'í': long.I, 'ú': long.U},
+		apostrophes: scheme.Apostrophes(),
+	}
+
+	letters := scheme.Letters(lang)
+	for r, from := range c.bahai {
+		if strings.ContainsAny(from, "aeiouáíú'") {
+			continue
+		}
+		c.consonants = append(c.consonants, consonantRule{letter: r, from: []rune(from), to: letters[r]})
+	}
+	sort.Slice(c.consonants, func(i, j int) bool {
+		a, b := c.consonants[i], c.consonants[j]
+		if len(a.from) != len(b.from) {
+			return len(a.from) > len(b.from)
+		}
+		return a.letter < b.letter
+	})
+
+	return c
+}
+
+// convert rewrites output, the Bahá'í transliteration of source. The source tells
+// apart the digraphs from clusters of two letters (dh for ذ or for د + ه in adham) and
+// ʿayn from hamza, which the Bahá'í scheme writes alike.
+func (c *schemeConverter) convert(source, output string) string {
+	runes := []rune(output)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	letters := sourceLetters(source)
+	apostrophes := c.classifyApostrophes(letters, lower)
+	digraphs := make(map[rune][]bool)
+	seen := make(map[rune]int)
+
+	var result strings.Builder
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == '\'' {
+			result.WriteString(apostrophes[0])
+			apostrophes = apostrophes[1:]
+			i++
+			continue
+		}
+		if long, ok := c.longVowels[lower[i]]; ok {
+			result.WriteString(capitalizeLike(long, r))
+			i++
+			continue
+		}
+
+		rule, ok := c.matchConsonant(lower[i:])
+		if !ok {
+			result.WriteRune(r)
+			i++
+			continue
+		}
+		if len(rule.from) > 1 {
+			if _, ok := digraphs[rule.letter]; !ok {
+				digraphs[rule.letter] = c.digraphSequence(letters, rule)
+			}
+			sequence := digraphs[rule.letter]
+			n := seen[rule.letter]
+			seen[rule.letter]++
+			if n < len(sequence) && !sequence[n] {
+				// Two letters written together: rewrite the first on its own
+				first, _ := c.matchConsonant(lower[i : i+1])
+				result.WriteString(capitalizeLike(first.to, r))
+				i++
+				continue
+			}
+		}
+		result.WriteString(capitalizeLike(rule.to, r))
+		i += len(rule.from)
+	}
+
+	return result.String()
+}
+
+// matchConsonant returns the consonant rule whose Bahá'í romanization starts text
+func (c *schemeConverter) matchConsonant(text []rune) (consonantRule, bool) {
+	for _, rule := range c.consonants {
+		if len(rule.from) <= len(text) && string(text[:len(rule.from)]) == string(rule.from) {
+			return rule, true
+		}
+	}
+	return consonantRule{}, false
+}
+
+// digraphSequence lists, in source order, whether each place the Bahá'í scheme writes
+// the digraph of rule is the letter itself (true) or two letters side by side (false)
+func (c *schemeConverter) digraphSequence(letters []rune, rule consonantRule) []bool {
+	var sequence []bool
+	for i := 0; i < len(letters); i++ {
+		if letters[i] == rule.letter {
+			sequence = append(sequence, true)
+			continue
+		}
+		if i+1 < len(letters) && c.bahai[letters[i]]+c.bahai[letters[i+1]] == string(rule.from) {
+			sequence = append(sequence, false)
+			i++
+		}
+	}
+	return sequence
+}
+
+// classifyApostrophes decides for every apostrophe of output (lower-cased) whether it
+// stands for ʿayn, hamza or the elided article, and returns the sign of each in the
+// scheme. The ʿayn and hamza letters of the source are matched in order with the
+// apostrophes that are not elisions; when their number differs, the letter the
+// source has decides.
+func (c *schemeConverter) classifyApostrophes(letters, output []rune) []string {
+	var elision []bool
+	elisions := 0
+	for i, r := range output {
+		if r == '\'' {
+			e := isElision(output, i)
+			elision = append(elision, e)
+			if e {
+				elisions++
+			}
+		}
+	}
+
+	written := writtenGlottals(letters)
+	if len(written) == len(elision) {
+		// Every apostrophe is a letter of the source
+		for i := range elision {
+			elision[i] = false
+		}
+	}
+
+	fallback := c.apostrophes.Hamza
+	for _, r := range written {
+		if r == 'ع' {
+			fallback = c.apostrophes.Ayn
+		}
+	}
+
+	signs := make([]string, len(elision))
+	next := 0
+	for i, e := range elision {
+		switch {
+		case e:
+			signs[i] = c.apostrophes.Elision
+		case next < len(written):
+			signs[i] = c.apostrophes.Hamza
+			if written[next] == 'ع' {
+				signs[i] = c.apostrophes.Ayn
+			}
+			next++
+		default:
+			signs[i] = fallback
+		}
+	}
+
+	return signs
+}
+
+// isElision reports whether the apostrophe at output[i] marks the elided alif of the
+// article: before l- or before the doubled sun letter it assimilates to (fí'd-dunyá),
+// and in the name Alláh ('lláh)
+func isElision(output []rune, i int) bool {
+	if i == 0 || !unicode.IsLetter(output[i-1]) {
+		return false
+	}
+	rest := string(output[i+1:])
+	if strings.HasPrefix(rest, "l-") || strings.HasPrefix(rest, "ll") {
+		return true
+	}
+	for _, sun := range []string{"t", "th", "d", "dh", "r", "z", "s", "sh", "ṣ", "ḍ", "ṭ", "ẓ", "n"} {
+		if strings.HasPrefix(rest, sun+"-"+sun) {
+			return true
+		}
+	}
+	return false
+}
+
+// writtenGlottals lists the ʿayn and hamza letters of a source the Bahá'í scheme
+// writes with an apostrophe. The hamza seat at the start of a word, after at most a
+// one-letter proclitic (wa-anta, bi-ism), is not written.
+func writtenGlottals(letters []rune) []rune {
+	var written []rune
+	wordStart := 0
+	for i, r := range letters {
+		if r == ' ' {
+			wordStart = i + 1
+			continue
+		}
+		switch r {
+		case 'ع', 'ء', 'ؤ', 'ئ', 'ۀ':
+			written = append(written, r)
+		case 'أ', 'إ', 'آ':
+			initial := i == wordStart || (i == wordStart+1 && strings.ContainsRune("وفبلك", letters[wordStart]))
+			if !initial {
+				written = append(written, r)
+			}
+		}
+	}
+	return written
+}
+
+// sourceLetters returns the letters of source without vowel marks, keeping spaces
+// between words
+func sourceLetters(source string) []rune {
+	var letters []rune
+	for _, r := range source {
+		switch {
+		case unicode.IsSpace(r):
+			letters = append(letters, ' ')
+		case isHaraka(r) || r == daggerAlif || r == madda || r == hamzaAbove || r == hamzaBelow:
+		default:
+			letters = append(letters, r)
+		}
+	}
+	return letters
+}
+
+// capitalizeLike capitalizes trans when original is an upper-case letter
+func capitalizeLike(trans string, original rune) string {
+	if unicode.IsUpper(original) {
+		return capitalize(trans)
+	}
+	return trans
+}
@@ -0,0 +1,73 @@
+package transliterator
+
+import "testing"
+
+func TestTransliterateScheme(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		lang     Language
+		scheme   Scheme
+		expected string
+	}{
+		{"bahai is the default", "يا إلهي اسمك شفائي", Arabic, SchemeBahai, "yá Iláhí, ismuka shifá'í"},
+		{"ala-lc long vowels and hamza", "يا إلهي اسمك شفائي", Arabic, SchemeALALC, "yā Ilāhī, ismuka shifāʼī"},
+		{"din digraphs", "جميع الخلق", Arabic, SchemeDIN, "ǧamīʿ al-ḫalq"},
+		{"elided article", "لا إله إلا الله", Arabic, SchemeDIN, "lā ilāha illāʾllāh"},
+		{"assimilated article", "في الدنيا", Arabic, SchemeDIN, "fīʾd-dunyā"},
+		{"capital digraph", "راه خدا", Persian, SchemeISO233, "rāh Xudā"},
+		{"two letters, not a digraph", "مَذْهَب", Arabic, SchemeDIN, "maḏhab"},
+		{"unknown word read with the scheme's letters", "دهخدا", Persian, SchemeISO233, "dahaxadā"},
+		{"ayn in persian", "بحرِ عطا", Persian, SchemeALALC, "baḥr-i ʻaṭā"},
+		{"phrase entry in ascii", "اسم کریم", Persian, SchemeASCII, "ism-i karim"},
+		{"ascii", "بحرِ عطا", Persian, SchemeASCII, "bahr-i 'ata"},
+		{"ascii keeps capitals", "راه خدا", Persian, SchemeASCII, "rah Khuda"},
+		{"latin text untouched", "Bahá'í", Arabic, SchemeDIN, "Bahá'í"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := trans.TransliterateScheme(tt.input, tt.lang, tt.scheme)
+			if result != tt.expected {
+				t.Errorf("TransliterateScheme(%q, %s) = %q, expected %q", tt.input, tt.scheme.Name(), result, tt.expected)
+			}
+		})
+	}
+
+	if result := trans.TransliterateScheme("جميع الخلق", Arabic, SchemeBahai); result != trans.Transliterate("جميع الخلق", Arabic) {
+		t.Errorf("bahai scheme = %q, expected the Transliterate output", result)
+	}
+}
+
+func TestSchemeLetters(t *testing.T) {
+	for _, name := range []string{"bahai", "ala-lc", "din", "iso233", "ascii"} {
+		scheme, err := SchemeByName(name)
+		if err != nil {
+			t.Fatalf("SchemeByName(%q) failed: %v", name, err)
+		}
+		if scheme.Name() != name {
+			t.Errorf("SchemeByName(%q).Name() = %q", name, scheme.Name())
+		}
+		for _, lang := range []Language{Arabic, Persian} {
+			if len(scheme.Letters(lang)) != len(bahaiLetters[lang]) {
+				t.Errorf("%s has %d letters, expected %d", name, len(scheme.Letters(lang)), len(bahaiLetters[lang]))
+			}
+		}
+	}
+
+	if _, err := SchemeByName("pinyin"); err == nil {
+		t.Errorf("SchemeByName(\"pinyin\") should fail")
+	}
+
+	din := SchemeDIN.Letters(Arabic)
+	for letter, expected := range map[rune]string{'ث': "ṯ", 'ا': "ā", 'ع': "ʿ", 'ء': "ʾ", 'ؤ': "uʾ"} {
+		if din[letter] != expected {
+			t.Errorf("DIN %c = %q, expected %q", letter, din[letter], expected)
+		}
+	}
+}
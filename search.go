@@ -0,0 +1,157 @@
+package transliterator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchResult is a transliteration together with the keys to index it by
+type SearchResult struct {
+	Text   string        // the transliteration, as returned by Transliterate
+	Key    string        // SearchKey of Text
+	Tokens []SearchToken // the tokens of the source, in order
+}
+
+// SearchToken gives the search key and the spelling variants of one token
+type SearchToken struct {
+	Source   string   // the token as written in the source text
+	Output   string   // the part of Text produced by the token
+	Key      string   // SearchKey of Output
+	Variants []string // other spellings of Output, when variants are asked for
+}
+
+// searchApostrophes are the signs written for ʿayn, hamza and elision across schemes
+// and keyboards
+const searchApostrophes = "'’‘ʿʾʻʼ`´"
+
+// searchFolds writes the accented letters of the romanization schemes in ASCII
+var searchFolds = map[rune]string{
+	'á': "a", 'à': "a", 'â': "a", 'ä': "a", 'ā': "a", 'ã': "a",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e", 'ē': "e",
+	'í': "i", 'ì': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'ó': "o", 'ò': "o", 'ô': "o", 'ö': "o", 'ō': "o",
+	'ú': "u", 'ù': "u", 'û': "u", 'ü': "u", 'ū': "u",
+	'ḥ': "h", 'ṣ': "s", 'ḍ': "d", 'ṭ': "t", 'ẓ': "z", 'ḳ': "k",
+	'ṯ': "th", 'ḏ': "dh", 'ḫ': "kh", 'ẖ': "kh", 'š': "sh", 'ǧ': "j",
+	'ġ': "gh", 'ğ': "gh", 'ž': "zh", 'č': "ch", 'ẕ': "z", 'ż': "z",
+}
+
+// searchSpellings are the spelling habits a reader may type instead of the Bahá'í
+// scheme, applied one at a time to build the variants of a word
+var searchSpellings = []struct {
+	from, to string
+	final    bool // only at the end of the word
+	persian  bool // only for Persian
+}{
+	{from: "v", to: "w", persian: true},
+	{from: "w", to: "v"},
+	{from: "ih", to: "eh", final: true},
+	{from: "ah", to: "a", final: true},
+	{from: "dh", to: "z", persian: true},
+	{from: "th", to: "s", persian: true},
+	{from: "q", to: "gh", persian: true},
+}
+
+// SearchKey folds a romanization into a plain ASCII key for search indexes: lower
+// case, without diacritics or apostrophes, with dot-under and scheme-specific letters
+// written as in the Bahá'í scheme and hyphens as spaces. "Bahá'u'lláh",
+// "Baha'u'llah" and "Bahaullah" share the key "bahaullah".
+func SearchKey(text string) string {
+	return strings.Join(strings.Fields(foldSearch(text, false)), " ")
+}
+
+// foldSearch lower-cases text and writes it in ASCII. Apostrophes are dropped or, with
+// keepApostrophes, unified to '. Hyphens become spaces unless apostrophes are kept.
+func foldSearch(text string, keepApostrophes bool) string {
+	var result strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case strings.ContainsRune(searchApostrophes, r):
+			if keepApostrophes {
+				result.WriteRune('\'')
+			}
+		case r == '-':
+			if keepApostrophes {
+				result.WriteRune('-')
+			} else {
+				result.WriteRune(' ')
+			}
+		case searchFolds[r] != "":
+			result.WriteString(searchFolds[r])
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			result.WriteRune(r)
+		case unicode.IsSpace(r):
+			result.WriteRune(' ')
+		}
+	}
+	return result.String()
+}
+
+// TransliterateForSearch transliterates text like Transliterate and returns the search
+// key of the result and of every token. With variants, each token also lists the
+// other spellings an index should accept for it: with and without apostrophes and
+// hyphens, and common alternatives (Tahirih and Tahireh, Naw-Ruz and Nav-Ruz).
+func (t *Transliterator) TransliterateForSearch(text string, lang Language, variants bool) SearchResult {
+	tokens := t.transliterateTokens(text, lang)
+	output, spans := t.render(tokens)
+
+	result := SearchResult{Text: output, Key: SearchKey(output)}
+	for i, tok := range tokens {
+		st := SearchToken{
+			Source: text[tok.start:tok.end],
+			Output: output[spans[i].start:spans[i].end],
+		}
+		st.Key = SearchKey(st.Output)
+		if variants {
+			st.Variants = searchVariants(st.Output, lang)
+		}
+		result.Tokens = append(result.Tokens, st)
+	}
+
+	return result
+}
+
+// searchVariants lists the spellings of a transliterated token an index should
+// accept, its search key first
+func searchVariants(output string, lang Language) []string {
+	var variants []string
+	seen := make(map[string]bool)
+	add := func(v string) {
+		v = strings.Join(strings.Fields(v), " ")
+		if v != "" && !seen[v] {
+			seen[v] = true
+			variants = append(variants, v)
+		}
+	}
+
+	withApostrophes := strings.Trim(foldSearch(output, true), " ")
+	key := SearchKey(output)
+	joined := strings.ReplaceAll(key, " ", "")
+
+	add(key)
+	add(withApostrophes)
+	add(strings.ReplaceAll(withApostrophes, "-", " "))
+	add(strings.ReplaceAll(withApostrophes, "-", ""))
+	add(joined)
+
+	for _, spelling := range searchSpellings {
+		if spelling.persian && lang != Persian {
+			continue
+		}
+		for _, form := range []string{key, joined} {
+			if spelling.final {
+				words := strings.Fields(form)
+				for i, word := range words {
+					if strings.HasSuffix(word, spelling.from) {
+						words[i] = strings.TrimSuffix(word, spelling.from) + spelling.to
+					}
+				}
+				add(strings.Join(words, " "))
+			} else {
+				add(strings.ReplaceAll(form, spelling.from, spelling.to))
+			}
+		}
+	}
+
+	return variants
+}
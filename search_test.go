@@ -0,0 +1,67 @@
+package transliterator
+
+import "testing"
+
+func TestSearchKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Bahá'u'lláh", "bahaullah"},
+		{"Baha'u'llah", "bahaullah"},
+		{"Bahaullah", "bahaullah"},
+		{"ʿAbdu’l-Bahá", "abdul baha"},
+		{"Ṭáhirih", "tahirih"},
+		{"šifāʾī", "shifai"}, // DIN letters written as in the Bahá'í scheme
+		{"yá Iláhí,  ismuka", "ya ilahi ismuka"},
+	}
+
+	for _, tt := range tests {
+		if result := SearchKey(tt.input); result != tt.expected {
+			t.Errorf("SearchKey(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestTransliterateForSearch(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	text := "يا إلهي، اسمك"
+	result := trans.TransliterateForSearch(text, Arabic, false)
+	if result.Text != trans.Transliterate(text, Arabic) {
+		t.Errorf("Text = %q, expected the Transliterate output %q", result.Text, trans.Transliterate(text, Arabic))
+	}
+	if result.Key != "ya ilahi ismuka" {
+		t.Errorf("Key = %q, expected %q", result.Key, "ya ilahi ismuka")
+	}
+	if len(result.Tokens) != 2 || result.Tokens[0].Key != "ya ilahi" || result.Tokens[0].Variants != nil {
+		t.Errorf("unexpected tokens: %+v", result.Tokens)
+	}
+
+	result = trans.TransliterateForSearch("طاهره", Persian, true)
+	expected := []string{"tahirih", "tahireh"}
+	variants := result.Tokens[0].Variants
+	if len(variants) != len(expected) {
+		t.Fatalf("variants = %q, expected %q", variants, expected)
+	}
+	for i := range expected {
+		if variants[i] != expected[i] {
+			t.Errorf("variants = %q, expected %q", variants, expected)
+		}
+	}
+
+	// Apostrophes and hyphens are optional in the variants
+	variants = searchVariants("'Abdu'l-Bahá", Arabic)
+	for _, want := range []string{"abdul baha", "'abdu'l-baha", "'abdu'l baha", "abdulbaha"} {
+		found := false
+		for _, v := range variants {
+			found = found || v == want
+		}
+		if !found {
+			t.Errorf("variants of 'Abdu'l-Bahá = %q, missing %q", variants, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DoltStore runs queries with the dolt command line tool in a local clone of the
+// database
+type DoltStore struct {
+	Dir  string // the directory of the clone
+	Push bool   // push to the remote after every commit
+
+	// run executes dolt with args in Dir, with input on its standard input; tests
+	// replace it
+	run func(input string, args ...string) ([]byte, error)
+}
+
+// NewDoltStore returns a store for the dolt clone in dir
+func NewDoltStore(dir string) *DoltStore {
+	s := &DoltStore{Dir: dir}
+	s.run = func(input string, args ...string) ([]byte, error) {
+		cmd := exec.Command("dolt", args...)
+		cmd.Dir = s.Dir
+		cmd.Stdin = strings.NewReader(input)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("dolt %s: %v, output: %s", args[0], err, string(output))
+		}
+		return output, nil
+	}
+	return s
+}
+
+// ListPairs returns the writings in sourceLang that have a row in targetLang
+func (s *DoltStore) ListPairs(sourceLang, targetLang string) ([]Pair, error) {
+	output, err := s.run("", "sql", "-q", bindQuery(listPairsQuery, sourceLang, targetLang), "-r", "csv")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(string(output))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV output: %v", err)
+	}
+
+	var pairs []Pair
+	// Skip header row
+	for i := 1; i < len(rows); i++ {
+		if len(rows[i]) < 5 {
+			continue // Skip malformed rows
+		}
+		pairs = append(pairs, Pair{
+			Version:  rows[i][0],
+			SourceID: rows[i][1],
+			Name:     rows[i][2],
+			Text:     rows[i][3],
+			Target:   rows[i][4],
+		})
+	}
+	return pairs, nil
+}
+
+// UpdateText replaces the text of the row with the given version
+func (s *DoltStore) UpdateText(version, text string) error {
+	_, err := s.run("", "sql", "-q", bindQuery(updateTextQuery, text, version))
+	return err
+}
+
+// UpdateTexts applies the updates in one transaction, run by a single dolt process.
+// The statements go through standard input, as a batch of long texts can exceed the
+// size the system allows for one argument.
+func (s *DoltStore) UpdateTexts(updates []Update) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	var script strings.Builder
+	script.WriteString("START TRANSACTION;\n")
+	for _, u := range updates {
+		script.WriteString(bindQuery(updateTextQuery, u.Text, u.Version))
+		script.WriteString(";\n")
+	}
+	script.WriteString("COMMIT;\n")
+
+	_, err := s.run(script.String(), "sql")
+	return err
+}
+
+// Commit stages every change and commits it, then pushes if Push is set
+func (s *DoltStore) Commit(msg string) error {
+	commands := [][]string{
+		{"add", "."},
+		{"commit", "-m", msg},
+	}
+	if s.Push {
+		commands = append(commands, []string{"push"})
+	}
+
+	for _, args := range commands {
+		if _, err := s.run("", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close does nothing, as every query runs its own dolt process
+func (s *DoltStore) Close() error {
+	return nil
+}
+
+// bindQuery replaces each ? of query with the next argument as a quoted string literal.
+// The dolt command line takes no query parameters.
+func bindQuery(query string, args ...string) string {
+	var result strings.Builder
+	for _, r := range query {
+		if r == '?' && len(args) > 0 {
+			result.WriteString(quoteString(args[0]))
+			args = args[1:]
+			continue
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// quoteEscapes are the characters escaped in a MySQL string literal
+var quoteEscapes = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+// quoteString writes s as a single-quoted MySQL string literal
+func quoteString(s string) string {
+	return "'" + quoteEscapes.Replace(s) + "'"
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Writing is a row of the writings table
+type Writing struct {
+	Version  string
+	Source   string
+	SourceID string
+	Language string
+	Name     string
+	Text     string
+}
+
+// MemoryStore keeps the writings table in memory, for tests and dry runs
+type MemoryStore struct {
+	Writings []Writing
+	Commits  []string // messages of the commits made so far
+}
+
+// ListPairs returns the writings in sourceLang that have a row in targetLang
+func (s *MemoryStore) ListPairs(sourceLang, targetLang string) ([]Pair, error) {
+	var pairs []Pair
+	for _, source := range s.Writings {
+		if source.Language != sourceLang {
+			continue
+		}
+		for _, target := range s.Writings {
+			if target.Language == targetLang && target.Source == source.Source && target.SourceID == source.SourceID {
+				pairs = append(pairs, Pair{
+					Version:  target.Version,
+					SourceID: source.SourceID,
+					Name:     source.Name,
+					Text:     source.Text,
+					Target:   target.Text,
+				})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].SourceID != pairs[j].SourceID {
+			return pairs[i].SourceID < pairs[j].SourceID
+		}
+		return pairs[i].Version < pairs[j].Version
+	})
+	return pairs, nil
+}
+
+// UpdateText replaces the text of the row with the given version
+func (s *MemoryStore) UpdateText(version, text string) error {
+	for i := range s.Writings {
+		if s.Writings[i].Version == version {
+			s.Writings[i].Text = text
+			return nil
+		}
+	}
+	return fmt.Errorf("no writing with version %s", version)
+}
+
+// UpdateTexts applies the updates, or none of them if a version is unknown
+func (s *MemoryStore) UpdateTexts(updates []Update) error {
+	rows := make(map[string]int)
+	for i, w := range s.Writings {
+		rows[w.Version] = i
+	}
+	for _, u := range updates {
+		if _, ok := rows[u.Version]; !ok {
+			return fmt.Errorf("no writing with version %s", u.Version)
+		}
+	}
+	for _, u := range updates {
+		s.Writings[rows[u.Version]].Text = u.Text
+	}
+	return nil
+}
+
+// Commit records msg
+func (s *MemoryStore) Commit(msg string) error {
+	s.Commits = append(s.Commits, msg)
+	return nil
+}
+
+// Close does nothing
+func (s *MemoryStore) Close() error {
+	return nil
+}
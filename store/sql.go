@@ -0,0 +1,111 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SQLStore runs queries through database/sql against a MySQL-protocol server, such as
+// dolt sql-server. The caller opens the connection with a registered driver.
+type SQLStore struct {
+	DB   *sql.DB
+	Push bool // push to the remote after every commit
+}
+
+// NewSQLStore returns a store for the database behind db
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{DB: db}
+}
+
+// OpenSQL connects to the MySQL-protocol server at dsn, such as
+// "root@tcp(127.0.0.1:3306)/bahaiwritings", through the driver registered as "mysql"
+// by the caller
+func OpenSQL(dsn string) (*SQLStore, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	return NewSQLStore(db), nil
+}
+
+// ListPairs returns the writings in sourceLang that have a row in targetLang
+func (s *SQLStore) ListPairs(sourceLang, targetLang string) ([]Pair, error) {
+	rows, err := s.DB.Query(listPairsQuery, sourceLang, targetLang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pairs: %v", err)
+	}
+	defer rows.Close()
+
+	var pairs []Pair
+	for rows.Next() {
+		var p Pair
+		if err := rows.Scan(&p.Version, &p.SourceID, &p.Name, &p.Text, &p.Target); err != nil {
+			return nil, fmt.Errorf("failed to read pair: %v", err)
+		}
+		pairs = append(pairs, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list pairs: %v", err)
+	}
+	return pairs, nil
+}
+
+// UpdateText replaces the text of the row with the given version
+func (s *SQLStore) UpdateText(version, text string) error {
+	if _, err := s.DB.Exec(updateTextQuery, text, version); err != nil {
+		return fmt.Errorf("failed to update %s: %v", version, err)
+	}
+	return nil
+}
+
+// UpdateTexts applies the updates in one transaction
+func (s *SQLStore) UpdateTexts(updates []Update) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(updateTextQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare update: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, u := range updates {
+		if _, err := stmt.Exec(u.Text, u.Version); err != nil {
+			return fmt.Errorf("failed to update %s: %v", u.Version, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// Commit stages every change and commits it with the dolt stored procedures, then
+// pushes if Push is set
+func (s *SQLStore) Commit(msg string) error {
+	if _, err := s.DB.Exec(`CALL DOLT_ADD('.')`); err != nil {
+		return fmt.Errorf("failed to stage changes: %v", err)
+	}
+	if _, err := s.DB.Exec(`CALL DOLT_COMMIT('-m', ?)`, msg); err != nil {
+		return fmt.Errorf("failed to commit: %v", err)
+	}
+	if s.Push {
+		if _, err := s.DB.Exec(`CALL DOLT_PUSH()`); err != nil {
+			return fmt.Errorf("failed to push: %v", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection
+func (s *SQLStore) Close() error {
+	return s.DB.Close()
+}
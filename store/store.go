@@ -0,0 +1,74 @@
+// Package store reads and writes the writings table of the bahaiwritings database
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Pair is a writing in a source language together with its row in a target language,
+// such as a Persian prayer and its fa-translit transliteration
+type Pair struct {
+	Version  string // version of the target row, which identifies it for UpdateText
+	SourceID string
+	Name     string
+	Text     string // text of the source row
+	Target   string // text of the target row
+}
+
+// Update is a new text for the row with the given version
+type Update struct {
+	Version string
+	Text    string
+}
+
+// WritingsStore gives access to the writings table
+type WritingsStore interface {
+	// ListPairs returns the writings in sourceLang that have a row in targetLang,
+	// ordered by source_id and then by the version of the target row
+	ListPairs(sourceLang, targetLang string) ([]Pair, error)
+	// UpdateText replaces the text of the row with the given version
+	UpdateText(version, text string) error
+	// UpdateTexts applies the updates in one transaction: all of them or none
+	UpdateTexts(updates []Update) error
+	// Commit records the changes made so far in the database history
+	Commit(msg string) error
+	// Close releases the connection to the database
+	Close() error
+}
+
+// The queries are shared by the stores, with ? for their arguments
+const (
+	listPairsQuery = `SELECT w2.version, w1.source_id, COALESCE(w1.name, '') AS name, w1.text, w2.text AS target ` +
+		`FROM writings w1 JOIN writings w2 ON w1.source = w2.source AND w1.source_id = w2.source_id ` +
+		`WHERE w1.language = ? AND w2.language = ? ORDER BY w1.source_id, w2.version`
+	updateTextQuery = `UPDATE writings SET text = ? WHERE version = ?`
+)
+
+var (
+	_ WritingsStore = (*DoltStore)(nil)
+	_ WritingsStore = (*SQLStore)(nil)
+	_ WritingsStore = (*MemoryStore)(nil)
+)
+
+// Open connects to the MySQL-protocol server at dsn, such as dolt sql-server, or, if
+// dsn is empty, to the dolt clone in dir. With push, every commit is pushed to the
+// remote. A dsn needs the driver OpenSQL uses.
+func Open(dir, dsn string, push bool) (WritingsStore, error) {
+	if dsn != "" {
+		s, err := OpenSQL(dsn)
+		if err != nil {
+			return nil, err
+		}
+		s.Push = push
+		return s, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".dolt")); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database path %s does not appear to be a dolt repository", dir)
+	}
+	s := NewDoltStore(dir)
+	s.Push = push
+	return s, nil
+}
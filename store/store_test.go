@@ -0,0 +1,184 @@
+package store
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func testWritings() []Writing {
+	return []Writing{
+		{Version: "v2", Source: "prayers", SourceID: "2", Language: "fa", Name: "Munáját", Text: "هو الله"},
+		{Version: "v2t", Source: "prayers", SourceID: "2", Language: "fa-translit", Text: "Húvállh"},
+		{Version: "v1", Source: "prayers", SourceID: "1", Language: "ar", Text: "يا إلهي"},
+		{Version: "v1t", Source: "prayers", SourceID: "1", Language: "ar-translit", Text: "Ya Iláhí"},
+		{Version: "v3", Source: "prayers", SourceID: "3", Language: "fa", Text: "بی ترجمه"},
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := &MemoryStore{Writings: testWritings()}
+
+	pairs, err := s.ListPairs("fa", "fa-translit")
+	if err != nil {
+		t.Fatalf("ListPairs failed: %v", err)
+	}
+	expected := []Pair{{Version: "v2t", SourceID: "2", Name: "Munáját", Text: "هو الله", Target: "Húvállh"}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("ListPairs = %+v, expected %+v", pairs, expected)
+	}
+
+	if err := s.UpdateText("v2t", "Huva'lláh"); err != nil {
+		t.Fatalf("UpdateText failed: %v", err)
+	}
+	if pairs, _ := s.ListPairs("fa", "fa-translit"); pairs[0].Target != "Huva'lláh" {
+		t.Errorf("UpdateText did not change the row: %+v", pairs)
+	}
+	if err := s.UpdateText("missing", "text"); err == nil {
+		t.Errorf("UpdateText should fail for an unknown version")
+	}
+
+	err = s.UpdateTexts([]Update{{"v1t", "Yá Iláhí"}, {"missing", "text"}})
+	if err == nil {
+		t.Errorf("UpdateTexts should fail for an unknown version")
+	}
+	if pairs, _ := s.ListPairs("ar", "ar-translit"); pairs[0].Target != "Ya Iláhí" {
+		t.Errorf("a failed UpdateTexts changed a row: %+v", pairs)
+	}
+	if err := s.UpdateTexts([]Update{{"v1t", "Yá Iláhí"}, {"v2t", "Huva"}}); err != nil {
+		t.Fatalf("UpdateTexts failed: %v", err)
+	}
+
+	if err := s.Commit("Update transliterations"); err != nil || len(s.Commits) != 1 {
+		t.Errorf("Commit = %v, commits %q", err, s.Commits)
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Bahá'u'lláh", `'Bahá\'u\'lláh'`},
+		{`a\'; DROP TABLE writings; --`, `'a\\\'; DROP TABLE writings; --'`},
+		{"line\nbreak", `'line\nbreak'`},
+		{"nul\x00", `'nul\0'`},
+	}
+	for _, tt := range tests {
+		if result := quoteString(tt.input); result != tt.expected {
+			t.Errorf("quoteString(%q) = %s, expected %s", tt.input, result, tt.expected)
+		}
+	}
+
+	query := bindQuery(updateTextQuery, "what's ?", "v1")
+	if query != `UPDATE writings SET text = 'what\'s ?' WHERE version = 'v1'` {
+		t.Errorf("bindQuery = %s", query)
+	}
+}
+
+func TestDoltStore(t *testing.T) {
+	s := NewDoltStore("/tmp/bahaiwritings")
+	var calls [][]string
+	var inputs []string
+	s.run = func(input string, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		inputs = append(inputs, input)
+		if len(args) > 2 && strings.HasPrefix(args[2], "SELECT") {
+			return []byte("version,source_id,name,text,target\nv1t,1,\"A, B\",\"يا إلهي\",\"Ya \"\"Iláhí\"\"\"\n"), nil
+		}
+		return nil, nil
+	}
+
+	pairs, err := s.ListPairs("ar", "ar-translit")
+	if err != nil {
+		t.Fatalf("ListPairs failed: %v", err)
+	}
+	expected := []Pair{{Version: "v1t", SourceID: "1", Name: "A, B", Text: "يا إلهي", Target: `Ya "Iláhí"`}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("ListPairs = %+v, expected %+v", pairs, expected)
+	}
+	if !strings.Contains(calls[0][2], "w1.language = 'ar' AND w2.language = 'ar-translit'") {
+		t.Errorf("ListPairs query = %s", calls[0][2])
+	}
+
+	calls = nil
+	if err := s.UpdateText("v1'", "Yá Iláhí"); err != nil {
+		t.Fatalf("UpdateText failed: %v", err)
+	}
+	if calls[0][2] != `UPDATE writings SET text = 'Yá Iláhí' WHERE version = 'v1\''` {
+		t.Errorf("UpdateText query = %s", calls[0][2])
+	}
+
+	calls, inputs = nil, nil
+	err = s.UpdateTexts([]Update{{"v1t", "Yá Iláhí"}, {"v2t", "it's"}})
+	if err != nil {
+		t.Fatalf("UpdateTexts failed: %v", err)
+	}
+	expectedScript := "START TRANSACTION;\n" +
+		"UPDATE writings SET text = 'Yá Iláhí' WHERE version = 'v1t';\n" +
+		"UPDATE writings SET text = 'it\\'s' WHERE version = 'v2t';\n" +
+		"COMMIT;\n"
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], []string{"sql"}) || inputs[0] != expectedScript {
+		t.Errorf("UpdateTexts ran %q with %q, expected one dolt sql with %q", calls, inputs, expectedScript)
+	}
+
+	calls = nil
+	s.Push = true
+	if err := s.Commit("Update transliterations"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	expectedCalls := [][]string{{"add", "."}, {"commit", "-m", "Update transliterations"}, {"push"}}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("Commit ran %q, expected %q", calls, expectedCalls)
+	}
+}
+
+// TestSQLStore runs against the server in WRITINGS_TEST_DSN, such as a local dolt
+// sql-server with a writings table
+func TestSQLStore(t *testing.T) {
+	dsn := os.Getenv("WRITINGS_TEST_DSN")
+	if dsn == "" {
+		t.Skip("WRITINGS_TEST_DSN is not set")
+	}
+
+	s, err := OpenSQL(dsn)
+	if err != nil {
+		t.Fatalf("OpenSQL failed: %v", err)
+	}
+	defer s.Close()
+
+	pairs, err := s.ListPairs("fa", "fa-translit")
+	if err != nil {
+		t.Fatalf("ListPairs failed: %v", err)
+	}
+	if len(pairs) == 0 {
+		t.Skip("no fa-translit rows")
+	}
+	if err := s.UpdateText(pairs[0].Version, pairs[0].Target); err != nil {
+		t.Errorf("UpdateText failed: %v", err)
+	}
+	if err := s.UpdateTexts([]Update{{pairs[0].Version, pairs[0].Target}}); err != nil {
+		t.Errorf("UpdateTexts failed: %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := Open(t.TempDir(), "", false); err == nil {
+		t.Errorf("Open should reject a directory without a dolt repository")
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(dir+"/.dolt", 0755); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(dir, "", true)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if dolt, ok := s.(*DoltStore); !ok || dolt.Dir != dir || !dolt.Push {
+		t.Errorf("Open = %+v, expected a pushing DoltStore for %s", s, dir)
+	}
+}
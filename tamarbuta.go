@@ -0,0 +1,112 @@
+package transliterator
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// taMarbuta is the letter ة, read t in connected speech and h in pause
+const taMarbuta = 'ة'
+
+// taMarbutaRules is the parsed ta_marbuta section of a dictionary: the ending of a
+// word written with ta marbuta in each context
+type taMarbutaRules struct {
+	Pausal        string `json:"pausal"`         // at the end of a phrase (raḥmah, Ṭáhirih)
+	Construct     string `json:"construct"`      // in iḍáfa and before a suffix (raḥmatuka)
+	BeforeArticle string `json:"before_article"` // before a word with the article (raḥmatu'lláh)
+}
+
+// parseTaMarbutaRules extracts the ta marbuta rules from a dictionary, if it has them
+func parseTaMarbutaRules(dict *Dictionary) *taMarbutaRules {
+	if dict.TaMarbuta == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(dict.TaMarbuta)
+	if err != nil {
+		return nil
+	}
+
+	rules := &taMarbutaRules{}
+	if err := json.Unmarshal(data, rules); err != nil || rules.Pausal == "" {
+		return nil
+	}
+	if rules.Construct == "" {
+		rules.Construct = rules.Pausal
+	}
+	if rules.BeforeArticle == "" {
+		rules.BeforeArticle = rules.Construct
+	}
+
+	return rules
+}
+
+// applyTaMarbuta resolves the ending of words written with a final ta marbuta from
+// their context: the construct form before a word with the article or an explicit
+// ezafe, the pausal form everywhere else. Readings that already carry a case
+// ending (raḥmatun) are left to the i'ráb policy.
+func (t *Transliterator) applyTaMarbuta(tokens []token, lang Language) []token {
+	rules := t.taMarbutas[lang]
+	if rules == nil {
+		return tokens
+	}
+
+	for i := range tokens {
+		source, _ := splitTrailingPunctuation(t.removeDiacritics(tokens[i].source))
+		output, punct := splitTrailingPunctuation(tokens[i].output)
+		stem, ok := taMarbutaStem(output)
+		if tokens[i].stage == StagePhrase || !strings.HasSuffix(source, string(taMarbuta)) || !ok || !strings.HasSuffix(output, "h") {
+			continue
+		}
+
+		form := rules.Pausal
+		switch {
+		case tokens[i].ezafe:
+			form = rules.Construct
+		case !isPausePosition(tokens, i) && t.startsWithArticle(tokens[i+1].source, lang):
+			form = rules.BeforeArticle
+		}
+		tokens[i].output = stem + form + punct
+	}
+
+	return tokens
+}
+
+// startsWithArticle reports whether word begins with the definite article. Persian
+// has no article of its own, so the Arabic one of quoted phrases is used instead.
+func (t *Transliterator) startsWithArticle(word string, lang Language) bool {
+	article := t.articles[lang]
+	if article == nil {
+		article = t.articles[Arabic]
+	}
+	if article == nil {
+		return false
+	}
+	_, ok := t.splitArticle(strings.Replace(word, "ٱ", "ا", 1), article)
+	return ok
+}
+
+// taMarbutaForm replaces the ta marbuta ending of a transliteration by the construct
+// or the pausal form of lang (raḥmah → raḥmat). Other endings are left alone.
+func (t *Transliterator) taMarbutaForm(trans string, lang Language, construct bool) string {
+	rules := t.taMarbutas[lang]
+	stem, ok := taMarbutaStem(trans)
+	if rules == nil || !ok {
+		return trans
+	}
+	if construct {
+		return stem + rules.Construct
+	}
+	return stem + rules.Pausal
+}
+
+// taMarbutaStem removes a ta marbuta ending, a short vowel followed by h or t
+// (-ah, -ih, -at), from a transliteration
+func taMarbutaStem(trans string) (string, bool) {
+	for _, ending := range []string{"ah", "ih", "eh", "at", "it"} {
+		if strings.HasSuffix(trans, ending) {
+			return strings.TrimSuffix(trans, ending), true
+		}
+	}
+	return trans, false
+}
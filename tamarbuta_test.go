@@ -0,0 +1,44 @@
+package transliterator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaMarbuta(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		lang     Language
+	}{
+		{"رحمة", "raḥmah", Arabic},               // pause
+		{"رحمة الله", "raḥmatu'lláh", Arabic},    // before the article
+		{"برحمتك", "bi-raḥmatika", Arabic},       // before a suffix
+		{"وَالآخِرَةِ", "wa'l-ákhirati", Arabic}, // vocalized: the case ending decides
+	}
+
+	for _, tt := range tests {
+		if got := trans.Transliterate(tt.input, tt.lang); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Persian reads the ta marbuta of Arabic loanwords as -ih in pause
+	if got := trans.Transliterate("رحمة", Persian); !strings.HasSuffix(got, "ih") {
+		t.Errorf("Transliterate(رحمة) = %q, expected a pausal -ih ending", got)
+	}
+
+	// The i'ráb policy reads a ta marbuta with tanwin in its pausal form
+	pausal, err := NewWithOptions(WithIrabPolicy(IrabPausal))
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	if got := pausal.Transliterate("رَحْمَةً", Arabic); got != "raḥmah" {
+		t.Errorf("Transliterate(رَحْمَةً) = %q, expected %q", got, "raḥmah")
+	}
+}
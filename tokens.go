@@ -0,0 +1,54 @@
+package transliterator
+
+import "unicode"
+
+// token is one whitespace-separated unit of the source text and its transliteration
+type token struct {
+	source  string // normalized text of the token
+	output  string // transliteration of the token
+	start   int    // byte offset of the token in the original text
+	end     int    // byte offset just past the token in the original text
+	stage   Stage  // pipeline step that produced the output
+	layer   string // dictionary layer of a phrase entry
+	attach  bool   // join to the previous token without a space
+	ezafe   bool   // the token carries an explicit ezafe mark
+	lineEnd bool   // the token is the last one on its line
+}
+
+// wordSpan is the position of a whitespace-separated word in a text
+type wordSpan struct {
+	start   int
+	end     int
+	lineEnd bool // no other word follows on the same line
+}
+
+// splitWords returns the spans of the whitespace-separated words of text, as
+// strings.Fields would split them
+func splitWords(text string) []wordSpan {
+	var spans []wordSpan
+	start := -1
+
+	for i, r := range text {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, wordSpan{start: start, end: i})
+			start = -1
+		}
+		if r == '\n' && len(spans) > 0 {
+			spans[len(spans)-1].lineEnd = true
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, wordSpan{start: start, end: len(text)})
+	}
+	if len(spans) > 0 {
+		spans[len(spans)-1].lineEnd = true
+	}
+
+	return spans
+}
@@ -1,13 +1,14 @@
 package transliterator
 
 import (
+	"embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
 	"regexp"
-	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Language represents the source language
@@ -33,15 +34,18 @@ type Dictionary struct {
 	EzafeRules           map[string]interface{} `json:"ezafe_rules"`
 	Heuristics           map[string]interface{} `json:"heuristics"`
 	VerbalPrefixes       map[string]WordEntry   `json:"verbal_prefixes"`
+	Proclitics           map[string]WordEntry   `json:"proclitics"`
 	Suffixes             map[string]interface{} `json:"suffixes"`
 	StressPatterns       map[string]interface{} `json:"stress_patterns"`
 	MorphologicalPatterns map[string]interface{} `json:"morphological_patterns"`
 	ConsonantChanges     map[string]interface{} `json:"consonant_changes"`
+	TaMarbuta            map[string]interface{} `json:"ta_marbuta"`
 }
 
 // WordEntry represents a dictionary entry
 type WordEntry struct {
 	Transliteration string `json:"transliteration"`
+	Phonemic        string `json:"phonemic,omitempty"` // scheme-neutral spelling, see RenderPhonemic
 	Category        string `json:"category"`
 	Notes           string `json:"notes"`
 	Root            string `json:"root"`
@@ -67,11 +71,26 @@ type Rule struct {
 type Transliterator struct {
 	arabicDict      *Dictionary
 	persianDict     *Dictionary
+	scheme          Scheme
 	arabicLetters   map[rune]string
 	persianLetters  map[rune]string
+	longVowels      *strings.Replacer
 	vowelMarks      map[rune]string
 	phraseTokens    map[string]string
 	minimalRegexes  []minimalRegex
+	layers          map[Language]*layerIndex
+	matchKeys       map[Language]map[string]string
+	phrases         map[Language]*phraseNode
+	reverse         map[Language]*reverseIndex
+	articles        map[Language]*definiteArticle
+	ezafes          map[Language]*ezafeRules
+	taMarbutas      map[Language]*taMarbutaRules
+	suffixes        map[Language][]suffixRule
+	proclitics      map[Language][]proclitic
+	wazns           map[Language][]wazn
+	roots           map[Language]map[string]bool
+	irab            IrabPolicy
+	quranicMarks    QuranicMarkPolicy
 }
 
 // minimalRegex represents essential regex patterns that cannot be handled by dictionary
@@ -82,17 +101,96 @@ type minimalRegex struct {
 	essential   bool // true if cannot be replaced by dictionary lookup
 }
 
-// New creates a new dictionary-first transliterator
+// embeddedDictionaries holds the default dictionaries compiled into the package
+//
+//go:embed data/arabic_dictionary.json data/persian_dictionary.json
+var embeddedDictionaries embed.FS
+
+// New creates a new dictionary-first transliterator using the embedded dictionaries
 func New() (*Transliterator, error) {
+	return NewWithOptions()
+}
+
+// NewWithOptions creates a new dictionary-first transliterator configured by opts
+func NewWithOptions(opts ...Option) (*Transliterator, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	t := &Transliterator{
 		phraseTokens: make(map[string]string),
+		scheme:       SchemeBahai,
+		irab:         o.irab,
+		quranicMarks: o.quranicMarks,
 	}
 
 	// Load dictionaries first
-	if err := t.loadDictionaries(); err != nil {
+	if err := t.loadDictionaries(o); err != nil {
 		return nil, fmt.Errorf("failed to load dictionaries: %v", err)
 	}
 
+	// Stack user dictionaries on top of the base dictionaries
+	if err := t.applyLayers(o.layers); err != nil {
+		return nil, fmt.Errorf("failed to apply dictionary layers: %v", err)
+	}
+
+	// Fill in the transliteration of entries that only have a phonemic spelling
+	if err := resolvePhonemic(t.arabicDict, Arabic); err != nil {
+		return nil, fmt.Errorf("invalid phonemic spelling in the Arabic dictionary: %v", err)
+	}
+	if err := resolvePhonemic(t.persianDict, Persian); err != nil {
+		return nil, fmt.Errorf("invalid phonemic spelling in the Persian dictionary: %v", err)
+	}
+
+	// Index the dictionary words by their spelling-variant keys
+	t.matchKeys = map[Language]map[string]string{
+		Arabic:  buildMatchIndex(t.arabicDict),
+		Persian: buildMatchIndex(t.persianDict),
+	}
+
+	// Index the common phrases word by word for longest-match lookup
+	t.phrases = map[Language]*phraseNode{
+		Arabic:  buildPhraseTrie(t.arabicDict),
+		Persian: buildPhraseTrie(t.persianDict),
+	}
+
+	// Index the dictionary entries by their transliteration for Detransliterate
+	t.reverse = map[Language]*reverseIndex{
+		Arabic:  buildReverseIndex(t.arabicDict),
+		Persian: buildReverseIndex(t.persianDict),
+	}
+
+	// Parse the article rules the dictionaries provide
+	t.articles = map[Language]*definiteArticle{
+		Arabic:  parseDefiniteArticle(t.arabicDict),
+		Persian: parseDefiniteArticle(t.persianDict),
+	}
+	t.ezafes = map[Language]*ezafeRules{
+		Arabic:  parseEzafeRules(t.arabicDict),
+		Persian: parseEzafeRules(t.persianDict),
+	}
+	t.taMarbutas = map[Language]*taMarbutaRules{
+		Arabic:  parseTaMarbutaRules(t.arabicDict),
+		Persian: parseTaMarbutaRules(t.persianDict),
+	}
+	t.suffixes = map[Language][]suffixRule{
+		Arabic:  parseSuffixes(t.arabicDict),
+		Persian: parseSuffixes(t.persianDict),
+	}
+	t.proclitics = map[Language][]proclitic{
+		Arabic:  parseProclitics(t.arabicDict),
+		Persian: parseProclitics(t.persianDict),
+	}
+	t.wazns = map[Language][]wazn{
+		Arabic:  parseWazns(t.arabicDict),
+		Persian: parseWazns(t.persianDict),
+	}
+	t.roots = map[Language]map[string]bool{
+		Arabic:  collectRoots(t.arabicDict),
+		Persian: collectRoots(t.persianDict),
+	}
+
 	// Initialize minimal letter mappings (fallback only)
 	t.initializeLetterMappings()
 
@@ -102,60 +200,79 @@ func New() (*Transliterator, error) {
 	return t, nil
 }
 
-// loadDictionaries loads the JSON dictionaries
-func (t *Transliterator) loadDictionaries() error {
+// loadDictionaries loads the JSON dictionaries from the configured filesystem
+func (t *Transliterator) loadDictionaries(o options) error {
 	// Load Arabic dictionary
-	arabicData, err := os.ReadFile("data/arabic_dictionary.json")
+	arabicDict, err := readDictionary(o.dictionaryFS, o.arabicPath)
 	if err != nil {
-		return fmt.Errorf("failed to read Arabic dictionary: %v", err)
+		return fmt.Errorf("failed to load Arabic dictionary: %v", err)
 	}
+	t.arabicDict = arabicDict
 
-	t.arabicDict = &Dictionary{}
-	if err := json.Unmarshal(arabicData, t.arabicDict); err != nil {
-		return fmt.Errorf("failed to parse Arabic dictionary: %v", err)
+	// Load Persian dictionary
+	persianDict, err := readDictionary(o.dictionaryFS, o.persianPath)
+	if err != nil {
+		return fmt.Errorf("failed to load Persian dictionary: %v", err)
 	}
+	t.persianDict = persianDict
 
-	// Load Persian dictionary
-	persianData, err := os.ReadFile("data/persian_dictionary.json")
+	return nil
+}
+
+// readDictionary reads and parses a single JSON dictionary from fsys
+func readDictionary(fsys fs.FS, path string) (*Dictionary, error) {
+	data, err := fs.ReadFile(fsys, path)
 	if err != nil {
-		return fmt.Errorf("failed to read Persian dictionary: %v", err)
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
 	}
 
-	t.persianDict = &Dictionary{}
-	if err := json.Unmarshal(persianData, t.persianDict); err != nil {
-		return fmt.Errorf("failed to parse Persian dictionary: %v", err)
+	dict := &Dictionary{}
+	if err := json.Unmarshal(data, dict); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
 	}
 
-	return nil
+	return dict, nil
 }
 
-// initializeLetterMappings sets up basic letter mappings as fallback
-func (t *Transliterator) initializeLetterMappings() {
-	// Arabic letters (minimal fallback set)
-	t.arabicLetters = map[rune]string{
-		'ا': "á", 'أ': "a", 'إ': "i", 'آ': "á", 'ب': "b", 'ت': "t", 'ث': "th", 'ج': "j", 'ح': "ḥ", 'خ': "kh",
-		'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s", 'ش': "sh", 'ص': "ṣ",
-		'ض': "ḍ", 'ط': "ṭ", 'ظ': "ẓ", 'ع': "'", 'غ': "gh", 'ف': "f", 'ق': "q",
-		'ك': "k", 'ک': "k", 'ل': "l", 'م': "m", 'ن': "n", 'ه': "h", 'و': "w", 'ي': "y",
-		'ى': "á", 'ة': "h", 'ء': "'", 'ؤ': "u'", 'ئ': "i'",
+// dictionary returns the merged dictionary for lang
+func (t *Transliterator) dictionary(lang Language) *Dictionary {
+	if lang == Persian {
+		return t.persianDict
 	}
+	return t.arabicDict
+}
 
-	// Persian letters (minimal fallback set)
-	t.persianLetters = map[rune]string{
-		'ا': "á", 'ب': "b", 'پ': "p", 'ت': "t", 'ث': "th", 'ج': "j", 'چ': "ch",
-		'ح': "ḥ", 'خ': "kh", 'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'ژ': "zh",
-		'س': "s", 'ش': "sh", 'ص': "ṣ", 'ض': "ḍ", 'ط': "ṭ", 'ظ': "ẓ", 'ع': "'",
-		'غ': "gh", 'ف': "f", 'ق': "q", 'ک': "k", 'گ': "g", 'ل': "l", 'م': "m",
-		'ن': "n", 'و': "v", 'ه': "h", 'ی': "í", 'ى': "á", 'ة': "h", 'ء': "'",
+// letterMap returns the fallback letter mapping for a language
+func (t *Transliterator) letterMap(lang Language) map[rune]string {
+	if lang == Persian {
+		return t.persianLetters
 	}
+	return t.arabicLetters
+}
+
+// initializeLetterMappings sets up the letter mappings of t's scheme as fallback
+func (t *Transliterator) initializeLetterMappings() {
+	t.arabicLetters = t.scheme.Letters(Arabic)
+	t.persianLetters = t.scheme.Letters(Persian)
+	long := t.scheme.LongVowels()
+	t.longVowels = strings.NewReplacer("á", long.A, "í", long.I, "ú", long.U)
 
 	// Diacritics
 	t.vowelMarks = map[rune]string{
 		'َ': "a", 'ِ': "i", 'ُ': "u", 'ً': "an", 'ٍ': "in", 'ٌ': "un",
-		'ْ': "", 'ّ': "", 'ٓ': "", 'ٔ': "", 'ٕ': "",
+		'ْ': "", 'ّ': "", 'ٓ': "", 'ٔ': "", 'ٕ': "", 'ٰ': long.A,
 	}
 }
 
+// inScheme returns a copy of t whose letter readings are written in scheme. The
+// dictionaries stay in the Bahá'í scheme.
+func (t *Transliterator) inScheme(scheme Scheme) *Transliterator {
+	st := *t
+	st.scheme = scheme
+	st.initializeLetterMappings()
+	return &st
+}
+
 // initializeEssentialPatterns sets up only the most essential regex patterns
 func (t *Transliterator) initializeEssentialPatterns() {
 	essentialPatterns := []struct {
@@ -168,8 +285,6 @@ func (t *Transliterator) initializeEssentialPatterns() {
 		{`\s+`, " ", "normalize spaces", true},
 		{`\s*-\s*`, "-", "normalize hyphens", true},
 		{`\s+([,.!?;:])`, "$1", "punctuation spacing", true},
-		{`'\s+`, "'", "apostrophe spacing", true},
-		{`\s+'`, "'", "apostrophe spacing", true},
 		
 		// Persian ezafe connector (essential structural element)
 		{`‌`, "-", "Persian ezafe connector", true},
@@ -193,60 +308,95 @@ func (t *Transliterator) initializeEssentialPatterns() {
 
 // Transliterate transliterates text using dictionary-first approach
 func (t *Transliterator) Transliterate(text string, lang Language) string {
-	// Handle phrase-level patterns first
-	text = t.handlePhrasesFromDict(text, lang)
+	tokens := t.transliterateTokens(text, lang)
 	
-	// Process word by word with dictionary priority
-	words := strings.Fields(text)
-	var result []string
+	// Join and apply minimal post-processing, keeping track of the tokens
+	output, _ := t.render(tokens)
 	
-	for _, word := range words {
-		transliterated := t.transliterateWordV2(word, lang)
-		result = append(result, transliterated)
-	}
-	
-	// Join and apply minimal post-processing
-	output := strings.Join(result, " ")
-	output = t.applyEssentialPostProcessing(output, lang)
-	
-	return strings.TrimSpace(output)
+	return output
 }
 
-// handlePhrasesFromDict handles multi-word phrases using dictionary data
-func (t *Transliterator) handlePhrasesFromDict(text string, lang Language) string {
-	var dict *Dictionary
-	if lang == Persian {
-		dict = t.persianDict
-	} else {
-		dict = t.arabicDict
+// transliterateTokens transliterates text word by word and applies the cross-word
+// rules, keeping the position of every token in text
+func (t *Transliterator) transliterateTokens(text string, lang Language) []token {
+	var words []token
+	for _, span := range splitWords(text) {
+		// Quranic marks per policy, then presentation forms folded, hamza and madda
+		// composed with their letters and tatweel dropped
+		word := normalizeText(t.applyQuranicMarks(text[span.start:span.end]))
+		if word == "" {
+			if span.lineEnd && len(words) > 0 {
+				words[len(words)-1].lineEnd = true
+			}
+			continue
+		}
+		words = append(words, token{source: word, start: span.start, end: span.end, lineEnd: span.lineEnd})
 	}
 	
-	// Check for common phrases in dictionary
-	if dict.CommonPhrases != nil {
-		// Sort phrases by length (longest first) to avoid partial matches
-		phrases := make([]string, 0, len(dict.CommonPhrases))
-		for phrase := range dict.CommonPhrases {
-			phrases = append(phrases, phrase)
+	// Dictionary phrases first, then word by word with dictionary priority
+	var tokens []token
+	for i := 0; i < len(words); {
+		if tok, n := t.matchPhrase(words[i:], lang); n > 0 {
+			tokens = append(tokens, tok)
+			i += n
+			continue
 		}
-		sort.Slice(phrases, func(i, j int) bool {
-			return len(phrases[i]) > len(phrases[j])
-		})
 		
-		for _, phrase := range phrases {
-			if entry, exists := dict.CommonPhrases[phrase]; exists {
-				text = strings.ReplaceAll(text, phrase, entry.Transliteration)
-			}
+		tok := words[i]
+		word, punct := splitTrailingPunctuation(tok.source)
+		word, tok.ezafe = t.splitEzafeMark(word, lang)
+		tok.output, tok.stage = t.transliterateWord(word, lang)
+		if word == "" && len(tokens) > 0 && !tokens[len(tokens)-1].lineEnd {
+			// Punctuation standing alone may repeat the comma the word before it
+			// ends with, and then disappears into that word
+			prev := tokens[len(tokens)-1].output
+			tok.output = appendPunctuation(prev, punct)[len(prev):]
+			tok.attach = tok.output == ""
+		} else {
+			tok.output = appendPunctuation(tok.output, punct)
 		}
+		tokens = append(tokens, tok)
+		i++
 	}
 	
-	return text
+	// Ta marbuta read as -at before the article, -ah or -ih in pause
+	tokens = t.applyTaMarbuta(tokens, lang)
+	
+	// Case endings kept or dropped according to the i'ráb policy
+	tokens = t.applyIrabPolicy(tokens, lang)
+	
+	// Cross-word contractions such as anta'l- and fí'd-
+	tokens = t.applyArticleElision(tokens, lang)
+	
+	// Persian ezafe (-i, -yi, -'i) from kasra marks and dictionary hints
+	tokens = t.applyPersianEzafe(tokens, lang)
+	
+	return tokens
+}
+
+// appendPunctuation adds the punctuation written after a word to its output. A comma
+// the output already ends with, as in the phrase "yá Iláhí,", is not written twice
+func appendPunctuation(output, punct string) string {
+	for _, r := range punct {
+		if r == ',' && strings.HasSuffix(output, ",") {
+			continue
+		}
+		output += string(r)
+	}
+	return output
 }
 
 // transliterateWordV2 uses dictionary-first approach for word transliteration
 func (t *Transliterator) transliterateWordV2(word string, lang Language) string {
+	output, _ := t.transliterateWord(word, lang)
+	return output
+}
+
+// transliterateWord transliterates a single word and reports the stage that produced it
+func (t *Transliterator) transliterateWord(word string, lang Language) (string, Stage) {
 	// Skip if no Arabic/Persian script
 	if !t.containsArabicScript(word) {
-		return word
+		return word, StagePassthrough
 	}
 	
 	// Get appropriate dictionary
@@ -260,30 +410,38 @@ func (t *Transliterator) transliterateWordV2(word string, lang Language) string
 	// Clean word for dictionary lookup
 	cleanWord := t.removeDiacritics(word)
 	
-	// Priority 1: Exact match in common words
-	if entry, exists := dict.CommonWords[cleanWord]; exists {
-		return entry.Transliteration
+	// A fully vocalized word is read from its marks; the dictionary only supplies
+	// segmentation and capitalization
+	if t.isFullyVocalized(word) {
+		return t.transliterateVocalized(word, cleanWord, lang), StageVocalized
 	}
 	
-	// Priority 2: Exact match in divine names
-	if dict.DivineNames != nil {
-		if entry, exists := dict.DivineNames[cleanWord]; exists {
-			return entry.Transliteration
-		}
+	// Priority 1 and 2: Exact match in common words, then divine names, across all layers
+	if entry, _, exists := t.lookupWord(cleanWord, lang); exists {
+		return entry.Transliteration, t.dictionaryStage(cleanWord, lang)
 	}
 	
-	// Priority 3: Compound word analysis using dictionary
+	// Priority 3: Definite article with sun/moon letter assimilation, alone or after
+	// an attached preposition (wa'l-, bi'l-, li'l-)
+	if article := t.analyzeArticle(word, lang); article != "" {
+		return article, StageArticle
+	}
+	if contracted := t.analyzePrepositionArticle(word, lang); contracted != "" {
+		return contracted, StageArticle
+	}
+	
+	// Priority 4: Compound word analysis using dictionary
 	if compound := t.analyzeCompoundWord(cleanWord, dict); compound != "" {
-		return compound
+		return compound, StageCompound
 	}
 	
-	// Priority 4: Morphological analysis using dictionary patterns
-	if morphological := t.analyzeMorphology(cleanWord, dict); morphological != "" {
-		return morphological
+	// Priority 5: Morphological analysis using dictionary patterns
+	if morphological := t.analyzeMorphology(cleanWord, lang); morphological != "" {
+		return morphological, StageMorphology
 	}
 	
-	// Priority 5: Fallback to heuristic with dictionary guidance
-	return t.dictionaryGuidedHeuristic(word, dict, lang)
+	// Priority 6: Fallback to heuristic with dictionary guidance
+	return t.dictionaryGuidedHeuristic(word, dict, lang), StageHeuristic
 }
 
 // analyzeCompoundWord attempts to break down compound words using dictionary
@@ -331,17 +489,6 @@ func (t *Transliterator) analyzePersianCompound(word string, dict *Dictionary) s
 	return ""
 }
 
-// analyzeMorphology attempts morphological analysis using dictionary patterns
-func (t *Transliterator) analyzeMorphology(word string, dict *Dictionary) string {
-	// Try to identify root + suffix patterns
-	if dict.Suffixes != nil {
-		// This would need proper implementation based on dictionary structure
-		// For now, return empty to use fallback
-	}
-	
-	return ""
-}
-
 // dictionaryGuidedHeuristic uses dictionary patterns to guide heuristic transliteration
 func (t *Transliterator) dictionaryGuidedHeuristic(word string, dict *Dictionary, lang Language) string {
 	var letterMap map[rune]string
@@ -351,6 +498,19 @@ func (t *Transliterator) dictionaryGuidedHeuristic(word string, dict *Dictionary
 		letterMap = t.arabicLetters
 	}
 	
+	// Partly vocalized words are read from the marks they have; only the letters left
+	// unmarked get an implied vowel
+	if t.removeDiacritics(word) != word {
+		return t.readVocalized(word, letterMap, true)
+	}
+	
+	// Unvocalized Arabic words are read through the morphological patterns first
+	if lang == Arabic {
+		if vocalized := t.vocalizeByPattern(word); vocalized != "" {
+			return vocalized
+		}
+	}
+	
 	// Use dictionary heuristics if available
 	if dict.Heuristics != nil {
 		// Apply dictionary-based vowel insertion patterns
@@ -369,7 +529,7 @@ func (t *Transliterator) applyDictionaryHeuristics(word string, dict *Dictionary
 	if dict.VowelPatterns != nil {
 		for pattern, replacement := range dict.VowelPatterns {
 			if pattern != "" && replacement.Transliteration != "" {
-				result = strings.ReplaceAll(result, pattern, replacement.Transliteration)
+				result = strings.ReplaceAll(result, pattern, t.longVowels.Replace(replacement.Transliteration))
 			}
 		}
 	}
@@ -379,37 +539,68 @@ func (t *Transliterator) applyDictionaryHeuristics(word string, dict *Dictionary
 
 // basicHeuristic provides basic letter-by-letter transliteration
 func (t *Transliterator) basicHeuristic(word string, letterMap map[rune]string) string {
-	var result strings.Builder
+	var pieces []string
 	runes := []rune(word)
 	
+	// Last consonant written, so that a shadda after it (and after its vowel) can double it
+	lastLetter, lastPiece := rune(0), -1
+	doubled := map[int]bool{}
+	
 	for _, r := range runes {
+		// Shadda doubles the preceding consonant, digraphs included (shsh, khkh)
+		if r == shadda {
+			if lastPiece >= 0 && !doubled[lastPiece] {
+				pieces[lastPiece] = geminate(lastLetter, pieces[lastPiece])
+				doubled[lastPiece] = true
+			}
+			continue
+		}
+		
 		// Handle diacritics
 		if vowel, exists := t.vowelMarks[r]; exists {
 			if vowel != "" {
-				result.WriteString(vowel)
+				pieces = append(pieces, vowel)
 			}
 			continue
 		}
 		
 		// Handle letters
 		if trans, exists := letterMap[r]; exists {
-			result.WriteString(trans)
+			pieces = append(pieces, trans)
+			lastLetter, lastPiece = r, len(pieces)-1
 		} else if t.containsArabicScript(string(r)) {
 			// Skip unmapped Arabic/Persian characters to prevent mixed output
 			continue
 		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			result.WriteRune(r)
+			pieces = append(pieces, string(r))
 		} else {
-			result.WriteRune(r)
+			pieces = append(pieces, string(r))
+		}
+	}
+	
+	// No vowel goes inside the transliteration of one letter (th, shsh)
+	joined := map[int]bool{}
+	position := 0
+	for _, piece := range pieces {
+		length := utf8.RuneCountInString(piece)
+		for i := 0; i < length-1; i++ {
+			joined[position+i] = true
 		}
+		position += length
 	}
 	
 	// Apply statistical vowel insertion as final fallback
-	return t.insertStatisticalVowels(result.String())
+	return t.insertVowelsBetween(strings.Join(pieces, ""), joined)
 }
 
 // insertStatisticalVowels adds vowels based on common patterns
 func (t *Transliterator) insertStatisticalVowels(consonantString string) string {
+	return t.insertVowelsBetween(consonantString, nil)
+}
+
+// insertVowelsBetween adds statistical vowels, except after the rune positions in
+// joined, which lie inside the transliteration of a single letter
+func (t *Transliterator) insertVowelsBetween(consonantString string, joined map[int]bool) string {
 	if len(consonantString) == 0 {
 		return consonantString
 	}
@@ -434,6 +625,11 @@ func (t *Transliterator) insertStatisticalVowels(consonantString string) string
 			continue
 		}
 		
+		// Keep digraphs (sh, kh) and consonants doubled by a shadda (ll, shsh) together
+		if i+1 < len(runes) && (isDigraph(r, runes[i+1]) || joined[i]) {
+			continue
+		}
+		
 		// Insert vowel based on statistical patterns
 		vowel := t.guessVowel(r, i, runes)
 		if vowel != "" {
@@ -515,7 +711,7 @@ func isVowel(r rune) bool {
 func (t *Transliterator) containsArabicScript(word string) bool {
 	for _, r := range word {
 		if (r >= 0x0600 && r <= 0x06FF) || (r >= 0x0750 && r <= 0x077F) ||
-			(r >= 0xFB50 && r <= 0xFDFF) || (r >= 0xFE70 && r <= 0xFEFF) {
+			(r >= 0x08A0 && r <= 0x08FF) || (r >= 0xFB50 && r <= 0xFDFF) || (r >= 0xFE70 && r <= 0xFEFF) {
 			return true
 		}
 	}
@@ -616,45 +812,7 @@ func (t *Transliterator) removeDiacritics(word string) string {
 
 // applyEssentialPostProcessing applies only essential post-processing patterns
 func (t *Transliterator) applyEssentialPostProcessing(text string, lang Language) string {
-	result := text
-	
-	// Apply essential patterns only
-	for _, processor := range t.minimalRegexes {
-		if processor.essential {
-			if processor.description == "sentence capitalization" || processor.description == "line capitalization" {
-				result = processor.regex.ReplaceAllStringFunc(result, func(match string) string {
-					parts := strings.Split(match, " ")
-					if len(parts) >= 2 {
-						lastPart := parts[len(parts)-1]
-						if len(lastPart) > 0 {
-							parts[len(parts)-1] = strings.ToUpper(lastPart[:1]) + lastPart[1:]
-						}
-					}
-					return strings.Join(parts, " ")
-				})
-			} else {
-				result = processor.regex.ReplaceAllString(result, processor.replacement)
-			}
-		}
-	}
-	
-	// Language-specific essential processing
-	if lang == Persian {
-		result = t.applyPersianEzafe(result)
-	}
-	
-	return result
-}
-
-// applyPersianEzafe applies Persian ezafe rules from dictionary
-func (t *Transliterator) applyPersianEzafe(text string) string {
-	// Use ezafe rules from dictionary if available
-	if t.persianDict.EzafeRules != nil {
-		// Apply ezafe connector rules
-		text = strings.ReplaceAll(text, "‌", "-")
-	}
-	
-	return text
+	return t.postProcess(text, nil)
 }
 
 // IsArabic checks if text is primarily Arabic
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 // TestData represents the structure of our JSON test files
@@ -156,6 +157,51 @@ func TestPersianTransliteration(t *testing.T) {
 	t.Logf("📊 Persian Overall: %d/%d tests passed, Average score: %.1f%%", passedTests, len(tests), averageScore)
 }
 
+func TestNewOutsideRepoRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("New should use the embedded dictionaries, got: %v", err)
+	}
+	if got := trans.Transliterate("الله", Arabic); got != "Alláh" {
+		t.Errorf("Expected Alláh from embedded dictionary, got %s", got)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"custom/ar.json": {Data: []byte(`{"common_words": {"كتاب": {"transliteration": "kitáb"}}}`)},
+		"custom/fa.json": {Data: []byte(`{"common_words": {"کتاب": {"transliteration": "kitáb"}}}`)},
+	}
+
+	trans, err := NewWithOptions(
+		WithDictionaryFS(fsys),
+		WithDictionaryPaths("custom/ar.json", "custom/fa.json"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+	if got := trans.Transliterate("كتاب", Arabic); got != "kitáb" {
+		t.Errorf("Expected kitáb from custom Arabic dictionary, got %s", got)
+	}
+	if got := trans.Transliterate("کتاب", Persian); got != "kitáb" {
+		t.Errorf("Expected kitáb from custom Persian dictionary, got %s", got)
+	}
+
+	// A missing path in the custom filesystem is an error, not a silent fallback
+	if _, err := NewWithOptions(WithDictionaryFS(fsys)); err == nil {
+		t.Errorf("Expected error when default paths are missing from the custom filesystem")
+	}
+}
+
 func TestLanguageDetection(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -510,4 +556,34 @@ func BenchmarkPersianTransliteration(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		trans.Transliterate(text, Persian)
 	}
-}
\ No newline at end of file
+}
+
+// Post-processing must not join a word ending in hamza or starting with ʿayn to its
+// neighbour; only the elided article attaches words
+func TestApostropheSpacing(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		input string
+		lang  Language
+		join  string
+	}{
+		{"سماء الله", Arabic, "' "},  // final hamza
+		{"جزاء عمل", Arabic, "' '"},  // final hamza, initial ʿayn
+		{"قال علي", Arabic, " '"},    // initial ʿayn
+		{"في الدنيا", Arabic, "'d-"}, // elided article
+	}
+
+	for _, tt := range tests {
+		got := trans.Transliterate(tt.input, tt.lang)
+		if !strings.Contains(got, tt.join) {
+			t.Errorf("Transliterate(%s) = %q, expected it to contain %q", tt.input, got, tt.join)
+		}
+		if words := len(strings.Fields(got)); words != len(strings.Fields(tt.input))-strings.Count(tt.join, "d-") {
+			t.Errorf("Transliterate(%s) = %q has %d words", tt.input, got, words)
+		}
+	}
+}
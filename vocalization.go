@@ -0,0 +1,452 @@
+package transliterator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Harakat and related marks read by the vocalized path (kasra and hamzaAbove are in ezafe.go)
+const (
+	fatha      = 'َ'
+	damma      = 'ُ'
+	sukun      = 'ْ'
+	shadda     = 'ّ'
+	fathatan   = 'ً'
+	kasratan   = 'ٍ'
+	dammatan   = 'ٌ'
+	madda      = 'ٓ'
+	hamzaBelow = 'ٕ'
+	daggerAlif = 'ٰ'
+)
+
+// shortVowels gives the transliteration of the vowel marks and tanwin
+var shortVowels = map[rune]string{
+	fatha: "a", kasra: "i", damma: "u",
+	fathatan: "an", kasratan: "in", dammatan: "un",
+	daggerAlif: "á",
+}
+
+// vocalizedLetter is one letter of a word together with the marks written on it
+type vocalizedLetter struct {
+	letter rune
+	vowel  rune // fatha, kasra, damma, tanwin or dagger alif; 0 when absent
+	sukun  bool
+	shadda bool
+	script bool // an Arabic-script letter, as opposed to punctuation or digits
+}
+
+// marked reports whether the letter carries a vowel or a sukun
+func (l vocalizedLetter) marked() bool {
+	return l.vowel != 0 || l.sukun
+}
+
+// parseVocalized groups the marks of a word with the letters they are written on,
+// composing alef with madda or hamza (ا + ٓ → آ) on the way
+func parseVocalized(word string) []vocalizedLetter {
+	var letters []vocalizedLetter
+
+	for _, r := range word {
+		var last *vocalizedLetter
+		if len(letters) > 0 {
+			last = &letters[len(letters)-1]
+		}
+
+		switch r {
+		case fatha, kasra, damma, fathatan, kasratan, dammatan, daggerAlif:
+			if last != nil {
+				last.vowel = r
+			}
+		case sukun:
+			if last != nil {
+				last.sukun = true
+			}
+		case shadda:
+			// A shadda typed after the alef of lam-alef (لاّ) belongs to the lam
+			if last != nil && last.letter == 'ا' && last.vowel == 0 && len(letters) > 1 {
+				last = &letters[len(letters)-2]
+			}
+			if last != nil {
+				last.shadda = true
+			}
+		case madda, hamzaAbove, hamzaBelow:
+			if last != nil && last.letter == 'ا' {
+				last.letter = map[rune]rune{madda: 'آ', hamzaAbove: 'أ', hamzaBelow: 'إ'}[r]
+			}
+		default:
+			letters = append(letters, vocalizedLetter{
+				letter: r,
+				script: unicode.Is(unicode.Arabic, r),
+			})
+		}
+	}
+
+	return letters
+}
+
+// isLongVowelLetter reports whether letters[i] only lengthens the vowel before it:
+// an unmarked alef or alef maqsura, waw after damma, ya after kasra
+func isLongVowelLetter(letters []vocalizedLetter, i int) bool {
+	l := letters[i]
+	if l.letter == 'ى' && l.vowel == daggerAlif {
+		return i > 0 // عَلَىٰ
+	}
+	if l.vowel != 0 || l.shadda {
+		return false
+	}
+
+	var prev rune
+	if i > 0 {
+		prev = letters[i-1].vowel
+	}
+
+	switch l.letter {
+	case 'ا', 'ى':
+		return i > 0
+	case 'و':
+		return prev == damma
+	case 'ي', 'ی':
+		return prev == kasra
+	}
+	return false
+}
+
+// isWaslAlef reports whether l is a bare alef that only carries the hamzat al-wasl
+func isWaslAlef(l vocalizedLetter) bool {
+	return (l.letter == 'ا' || l.letter == 'ٱ') && !l.marked()
+}
+
+// impliedVowels fills in the vowels a partly vocalized word leaves out: the vowel that
+// matches a following long vowel letter (damma before waw, kasra before ya), fatha
+// otherwise. A consonant before a letter that carries its vowel is left without one
+// (ma'búd), and the consonant before a final bare alif takes the tanwin fath that
+// alif carries (ma'búdan), unless it spells a suffix (-há, -ná) or -yá. Marked
+// letters, the last letter and vowel letters are left alone.
+func impliedVowels(letters []vocalizedLetter) []vocalizedLetter {
+	filled := append([]vocalizedLetter(nil), letters...)
+
+	last := -1
+	for i, l := range filled {
+		if l.script {
+			last = i
+		}
+	}
+
+	for i := 0; i < last; i++ {
+		l := filled[i]
+		if !l.script || l.marked() || isLongVowelLetter(filled, i) {
+			continue
+		}
+		switch l.letter {
+		case 'ا', 'ٱ', 'أ', 'إ', 'آ', 'ة':
+			continue
+		}
+
+		next := filled[i+1]
+		switch {
+		case i+1 == last && next.letter == 'ا' && !next.marked() && !isYa(l.letter) && l.letter != 'ه' && l.letter != 'ن':
+			filled[i].vowel = fathatan
+		case i > 0 && next.script && next.vowel != 0:
+			// no vowel: a word-initial consonant always has one
+		case next.letter == 'و' && !next.marked():
+			filled[i].vowel = damma
+		case isYa(next.letter) && next.shadda && i == 0:
+			filled[i].vowel = fatha // sayyid, ṭayyib
+		case isYa(next.letter) && !next.marked():
+			filled[i].vowel = kasra // long í, or -iyy- before a doubled ya (vaḥdániyyat)
+		default:
+			filled[i].vowel = fatha
+		}
+	}
+
+	return filled
+}
+
+// isFullyVocalized reports whether every letter of word that needs a vowel carries a
+// mark, so the word can be read from its harakat alone. The last letter may be left
+// unmarked (pausal form), as may the alef and lam of the article and letters whose
+// vowel follows from a long vowel letter or ta marbuta after them.
+func (t *Transliterator) isFullyVocalized(word string) bool {
+	letters := parseVocalized(word)
+
+	last := -1
+	hasMarks := false
+	for i, l := range letters {
+		if l.script {
+			last = i
+		}
+		// A shadda alone marks the word, so ايّام is read from its harakat and not
+		// matched to the dictionary entry ایام
+		if l.marked() || l.shadda {
+			hasMarks = true
+		}
+	}
+	if !hasMarks {
+		return false
+	}
+
+	for i, l := range letters {
+		if !l.script || i == last || l.marked() || isLongVowelLetter(letters, i) {
+			continue
+		}
+		if isWaslAlef(l) || l.letter == 'آ' || l.letter == 'إ' {
+			continue // alef of a hamzat al-wasl, or one whose vowel is implied by its seat
+		}
+		if l.letter == 'ل' && i > 0 && isWaslAlef(letters[i-1]) {
+			continue // lam of the article
+		}
+		if next := letters[i+1].letter; next == 'ا' || next == 'ى' || next == 'ة' {
+			continue // implied fatha
+		}
+		return false
+	}
+
+	return true
+}
+
+// readVocalized transliterates a word from its harakat: shadda doubles the consonant,
+// sukun leaves it without vowel, fatha, kasra and damma before alef, ya and waw become
+// long vowels, and tanwin is written as -an, -in, -un. With guess set, consonants the
+// word leaves unmarked get an implied vowel; otherwise they get none. Long vowels and
+// hamza are written in t's scheme.
+func (t *Transliterator) readVocalized(word string, letterMap map[rune]string, guess bool) string {
+	letters := parseVocalized(word)
+	if guess {
+		letters = impliedVowels(letters)
+	}
+
+	var result strings.Builder
+	pending := "" // vowel of the previous letter, which a long vowel letter may still lengthen
+	flush := func() {
+		result.WriteString(t.longVowels.Replace(pending))
+		pending = ""
+	}
+
+	for i, l := range letters {
+		var next *vocalizedLetter
+		if i+1 < len(letters) {
+			next = &letters[i+1]
+		}
+
+		switch {
+		case !l.script:
+			flush()
+			result.WriteRune(l.letter)
+
+		case isLongVowelLetter(letters, i):
+			switch {
+			case pending == "an" && l.letter == 'ا':
+				// the alef carrying tanwin fath is silent
+			case pending == "" || pending == "a":
+				pending = "á"
+			case pending == "í" && l.letter == 'ا':
+				pending = "íyá" // the ya before an alef was a consonant after all (tajallíyát)
+			case pending == "i":
+				pending = "í"
+			case pending == "u":
+				pending = "ú"
+			}
+
+		case l.letter == 'ا' || l.letter == 'ٱ' || l.letter == 'أ' || l.letter == 'إ' || l.letter == 'آ':
+			// Alef as a seat of a vowel or hamza; the hamza is not written word-initially
+			flush()
+			if i > 0 && l.letter != 'ا' && l.letter != 'ٱ' {
+				result.WriteString(t.scheme.Apostrophes().Hamza)
+			}
+			switch {
+			case l.letter == 'آ':
+				pending = "á"
+			case l.vowel != 0:
+				pending = shortVowels[l.vowel]
+			case l.letter == 'إ':
+				pending = "i"
+			case next != nil && next.sukun:
+				pending = "i"
+			case !l.sukun:
+				pending = "a"
+			}
+
+		case l.letter == 'ء' || l.letter == 'ؤ' || l.letter == 'ئ':
+			flush()
+			result.WriteString(t.scheme.Apostrophes().Hamza)
+			pending = shortVowels[l.vowel]
+
+		case l.letter == 'ة':
+			if pending == "" {
+				pending = "a"
+			}
+			flush()
+			if l.vowel != 0 {
+				result.WriteString("t")
+				pending = shortVowels[l.vowel]
+			} else {
+				result.WriteString(letterMap[l.letter])
+			}
+
+		default:
+			flush()
+			consonant, exists := letterMap[l.letter]
+			if isYa(l.letter) && (l.vowel != 0 || l.shadda || !exists) {
+				// Ya carrying a vowel or shadda is the consonant y, also in Persian
+				// where the letter map only knows it as the long vowel í
+				consonant, exists = "y", true
+			}
+			if !exists {
+				continue
+			}
+			if l.shadda {
+				consonant = geminate(l.letter, consonant)
+			}
+			result.WriteString(consonant)
+			pending = shortVowels[l.vowel]
+		}
+	}
+	flush()
+
+	return result.String()
+}
+
+// isYa reports whether r is the Arabic or the Persian ya
+func isYa(r rune) bool {
+	return r == 'ي' || r == 'ی'
+}
+
+// geminate doubles a consonant for shadda. Digraphs double as a whole (shsh, khkh)
+// and a doubled waw is ww in Persian as well (munawwar).
+func geminate(letter rune, consonant string) string {
+	if letter == 'و' {
+		return "ww"
+	}
+	return consonant + consonant
+}
+
+// isDigraph reports whether first and second spell one consonant (sh, kh, gh, ...).
+// dh and th are left out: in Persian they are as often d or t followed by a final h.
+func isDigraph(first, second rune) bool {
+	return second == 'h' && strings.ContainsRune("skgzc", first)
+}
+
+// transliterateVocalized reads a fully vocalized word from its marks, keeping the
+// article and proclitic segmentation of the dictionary path and taking capitalization
+// from the dictionary entry of the word, if any
+func (t *Transliterator) transliterateVocalized(word, cleanWord string, lang Language) string {
+	result := t.analyzeArticle(word, lang)
+	if result == "" {
+		result = t.analyzePrepositionArticle(word, lang)
+	}
+	if result == "" && lang == Arabic {
+		result = t.readVocalizedClitics(word, cleanWord)
+	}
+	if result == "" {
+		result = t.readVocalized(word, t.letterMap(lang), false)
+	}
+
+	if entry, _, exists := t.lookupWord(cleanWord, lang); exists {
+		result = matchCase(result, entry.Transliteration)
+	}
+	return result
+}
+
+// readVocalizedClitics hyphenates the proclitics of a vocalized word and reads the
+// remainder from its marks (وَذِكْرُكَ → wa-dhikruka, وَبِالاسْمِ → wa-bi'l-ismi)
+func (t *Transliterator) readVocalizedClitics(word, cleanWord string) string {
+	prefixes := t.vocalizedProclitics(cleanWord)
+	if len(prefixes) == 0 {
+		return ""
+	}
+
+	rest := word
+	for _, p := range prefixes {
+		stripped, ok := t.splitPrefix(rest, p.spelling)
+		if !ok {
+			return ""
+		}
+		rest = stripped
+	}
+	restTrans := t.transliterateWordV2(rest, Arabic)
+
+	var result strings.Builder
+	for _, p := range prefixes[:len(prefixes)-1] {
+		result.WriteString(p.transliteration + "-")
+	}
+	last := prefixes[len(prefixes)-1]
+	if article := t.articles[Arabic]; article != nil {
+		if elided, ok := t.elidedArticle(rest, restTrans, article); ok {
+			return result.String() + last.transliteration + elided
+		}
+	}
+	result.WriteString(last.transliteration + "-" + restTrans)
+
+	return result.String()
+}
+
+// vocalizedProclitics finds the proclitics of a word: those of the clitic segmenter,
+// those before a definite article, or those the dictionary entry of the word hyphenates
+func (t *Transliterator) vocalizedProclitics(cleanWord string) []proclitic {
+	if segmentation, ok := t.segmentClitics(cleanWord); ok {
+		return segmentation.prefixes
+	}
+
+	article := t.articles[Arabic]
+	entry, _, known := t.lookupWord(cleanWord, Arabic)
+
+	for _, split := range t.procliticSplits(cleanWord) {
+		if len(split.prefixes) == 0 {
+			continue
+		}
+		if article != nil {
+			if _, ok := t.splitArticle(split.rest, article); ok {
+				return split.prefixes
+			}
+		}
+
+		var hyphenated strings.Builder
+		for _, p := range split.prefixes {
+			hyphenated.WriteString(p.transliteration + "-")
+		}
+		if known && strings.HasPrefix(strings.ToLower(entry.Transliteration), hyphenated.String()) {
+			return split.prefixes
+		}
+	}
+
+	return nil
+}
+
+// matchCase capitalizes the hyphen-separated parts of reading whose counterparts are
+// capitalized in the dictionary transliteration (al-muqtadiru, al-Muqtadir → al-Muqtadiru)
+func matchCase(reading, entry string) string {
+	readingParts := strings.Split(reading, "-")
+	entryParts := strings.Split(entry, "-")
+	if len(readingParts) != len(entryParts) {
+		readingParts = []string{reading}
+		entryParts = []string{entry}
+	}
+
+	for i, part := range entryParts {
+		if startsUpper(part) {
+			readingParts[i] = capitalize(readingParts[i])
+		}
+	}
+
+	return strings.Join(readingParts, "-")
+}
+
+// startsUpper reports whether the first letter of s, after any apostrophes, is uppercase
+func startsUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return unicode.IsUpper(r)
+		}
+	}
+	return false
+}
+
+// capitalize uppercases the first letter of s, skipping leading apostrophes ('Alím)
+func capitalize(s string) string {
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			return s[:i] + string(unicode.ToUpper(r)) + s[i+utf8.RuneLen(r):]
+		}
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package transliterator
+
+import "testing"
+
+func TestVocalizedInput(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"long vowels and hamza", "شِفائِي", "shifá'í"},
+		{"sukun", "اسْمُكَ", "ismuka"},
+		{"shadda", "مُحَمَّدٌ", "muḥammadun"},
+		{"tanwin fath on alef", "شُكْرًا", "shukran"},
+		{"tanwin after hamza", "سَمَاءٌ", "samá'un"},
+		{"madda", "آمَنَ", "ámana"},
+		{"dagger alif", "هٰذَا", "hádhá"},
+		{"dagger alif on alef maqsura", "عَلَىٰ", "'alá"},
+		{"ya with sukun after kasra", "وَمُعِيْنِيْ", "wa-mu'íní"},
+		{"proclitic from the segmenter", "وَذِكْرُكَ", "wa-dhikruka"},
+		{"proclitic before the article", "وَبِالاسْمِ", "wa-bi'l-ismi"},
+		{"doubled lam is not an article", "الَّذِي", "alladhí"},
+		{"capitalization from the dictionary", "الْمُقْتَدِرُ", "al-Muqtadiru"},
+		{"partly vocalized word uses the dictionary", "إِلهِي", "Iláhí"},
+		{"partly vocalized word without entry", "مَعبُودا", "ma'búdan"},
+		{"pronoun suffix before the final alif", "كُلّها", "kulluhá"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trans.Transliterate(tt.input, Arabic); got != tt.expected {
+				t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	for word, expected := range map[string]bool{
+		"شِفائِي":     true,
+		"وَالآخِرَةِ": true,
+		"إِلهِي":      false, // the lam has no mark
+		"الله":        false,
+	} {
+		if got := trans.isFullyVocalized(word); got != expected {
+			t.Errorf("isFullyVocalized(%s) = %v, expected %v", word, got, expected)
+		}
+	}
+}
+
+func TestShaddaGemination(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	// Geminated words from the test case prayers
+	tests := []struct {
+		input    string
+		expected string
+		lang     Language
+	}{
+		{"وَحُبُّكَ", "wa-ḥubbuka", Arabic},
+		{"وَإِنَّكَ", "wa-innaka", Arabic},
+		{"كُلِّ", "kulli", Arabic},
+		{"وَقُوَّتِكَ", "wa-quwwatika", Arabic},
+		{"إِلاّ", "illá", Arabic},           // shadda typed after the alef of lam-alef
+		{"الدُّنْيا", "ad-dunyá", Arabic},   // sun letter: the article already doubles it
+		{"الشُّكْرُ", "ash-shukru", Arabic}, // digraph sun letter
+		{"النِّعْمَةِ", "an-ni'mati", Arabic},
+		{"الْقَيُّومُ", "al-Qayyúmu", Arabic},
+		{"ايّام", "ayyám", Persian},
+		{"مُنَوّر", "munawwar", Persian},
+		{"فَضّالی", "faḍḍálí", Persian},
+		{"تجلّياتِ", "tajallíyát-i", Persian},
+		{"ذرّاتِ", "dharrát-i", Persian},
+	}
+
+	for _, tt := range tests {
+		if got := trans.Transliterate(tt.input, tt.lang); got != tt.expected {
+			t.Errorf("Transliterate(%s) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Digraphs double as a whole and keep no vowel between their halves
+	for input, expected := range map[string]string{"مشّر": "mashshar", "مخّ": "makhkh", "ثنّ": "thann"} {
+		if got := trans.basicHeuristic(input, trans.arabicLetters); got != expected {
+			t.Errorf("basicHeuristic(%s) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package transliterator
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Placeholder letters of the root consonants in a morphological pattern (فعل)
+const (
+	waznFa  = 'ف'
+	waznAyn = 'ع'
+	waznLam = 'ل'
+)
+
+// wazn is a parsed morphological_patterns entry: an Arabic template such as مفعول
+// with its transliteration, where 1, 2 and 3 stand for the root consonants (ma12ú3)
+type wazn struct {
+	Name        string  `json:"-"`
+	Pattern     string  `json:"pattern"`
+	Template    string  `json:"template"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight"`
+}
+
+// Vocalization is one proposed reading of an unvocalized Arabic word
+type Vocalization struct {
+	Transliteration string
+	Pattern         string // name of the morphological pattern, e.g. "maf3ool"
+	Root            string // root consonants in dictionary notation, e.g. "ق-ص-د"
+	KnownRoot       bool   // the root appears in the Root field of a dictionary entry
+}
+
+// parseWazns extracts the templates of the morphological_patterns section that have a
+// transliteration template; descriptive entries (e.g. Persian compound verbs) are skipped
+func parseWazns(dict *Dictionary) []wazn {
+	var result []wazn
+	for name, raw := range dict.MorphologicalPatterns {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			continue
+		}
+		var w wazn
+		if err := json.Unmarshal(data, &w); err != nil || w.Pattern == "" || w.Template == "" {
+			continue
+		}
+		w.Name = name
+		result = append(result, w)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// collectRoots indexes the Root fields of all dictionary words, alef variants folded
+func collectRoots(dict *Dictionary) map[string]bool {
+	roots := make(map[string]bool)
+	for _, entries := range []map[string]WordEntry{dict.CommonWords, dict.DivineNames} {
+		for _, entry := range entries {
+			if entry.Root != "" {
+				roots[foldAlef(entry.Root)] = true
+			}
+		}
+	}
+	return roots
+}
+
+// Vocalizations matches an unvocalized Arabic word against the morphological patterns of
+// the dictionary and returns the possible readings, best first. Readings whose root is
+// in the dictionary come first, then more specific patterns, then the pattern weight.
+func (t *Transliterator) Vocalizations(word string) []Vocalization {
+	type candidate struct {
+		Vocalization
+		specificity int
+		weight      float64
+	}
+
+	clean := []rune(foldAlef(t.removeDiacritics(word)))
+	var candidates []candidate
+
+	for _, w := range t.wazns[Arabic] {
+		pattern := []rune(foldAlef(w.Pattern))
+		if len(pattern) != len(clean) {
+			continue
+		}
+
+		root := make(map[rune]rune)
+		specificity := 0
+		matched := true
+		for i, p := range pattern {
+			switch p {
+			case waznFa, waznAyn, waznLam:
+				root[p] = rootLetter(clean[i])
+			default:
+				if clean[i] != p {
+					matched = false
+				}
+				specificity++
+			}
+			if !matched {
+				break
+			}
+		}
+		if !matched || len(root) != 3 {
+			continue
+		}
+		// Roots are written with hamza, never with alef; an alef here is a long vowel
+		if root[waznFa] == 'ا' || root[waznAyn] == 'ا' || root[waznLam] == 'ا' {
+			continue
+		}
+
+		trans, ok := t.fillTemplate(w.Template, root)
+		if !ok {
+			continue
+		}
+		rootKey := string([]rune{root[waznFa], '-', root[waznAyn], '-', root[waznLam]})
+		candidates = append(candidates, candidate{
+			Vocalization: Vocalization{
+				Transliteration: trans,
+				Pattern:         w.Name,
+				Root:            rootKey,
+				KnownRoot:       t.roots[Arabic][rootKey],
+			},
+			specificity: specificity,
+			weight:      w.Weight,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.KnownRoot != b.KnownRoot {
+			return a.KnownRoot
+		}
+		if a.specificity != b.specificity {
+			return a.specificity > b.specificity
+		}
+		return a.weight > b.weight
+	})
+
+	result := make([]Vocalization, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.Vocalization
+	}
+	return result
+}
+
+// vocalizeByPattern returns the best pattern reading of an unvocalized word when it is
+// trustworthy: the root is known, or the pattern has letters of its own (مفعول, تفعيل)
+// rather than being a bare three-consonant skeleton
+func (t *Transliterator) vocalizeByPattern(word string) string {
+	if t.removeDiacritics(word) != word {
+		return ""
+	}
+
+	candidates := t.Vocalizations(word)
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	best := candidates[0]
+	if !best.KnownRoot && len([]rune(word)) <= 3 {
+		return ""
+	}
+	return best.Transliteration
+}
+
+// fillTemplate substitutes the transliterated root consonants into a template
+func (t *Transliterator) fillTemplate(template string, root map[rune]rune) (string, bool) {
+	placeholders := map[rune]rune{'1': waznFa, '2': waznAyn, '3': waznLam}
+
+	var result strings.Builder
+	for _, r := range template {
+		placeholder, isPlaceholder := placeholders[r]
+		if !isPlaceholder {
+			result.WriteString(t.longVowels.Replace(string(r)))
+			continue
+		}
+		letter, exists := t.arabicLetters[root[placeholder]]
+		if !exists {
+			return "", false
+		}
+		result.WriteString(letter)
+	}
+
+	return result.String(), true
+}
+
+// rootLetter writes a hamza on a carrier (ؤ ئ) as the bare hamza of the root
+func rootLetter(r rune) rune {
+	if r == 'ؤ' || r == 'ئ' {
+		return 'ء'
+	}
+	return r
+}
+
+// foldAlef writes all alef forms (أ إ آ ٱ) as a bare alef
+func foldAlef(s string) string {
+	return strings.NewReplacer("أ", "ا", "إ", "ا", "آ", "ا", "ٱ", "ا").Replace(s)
+}
@@ -0,0 +1,49 @@
+package transliterator
+
+import "testing"
+
+func TestVocalizations(t *testing.T) {
+	trans, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create transliterator: %v", err)
+	}
+
+	tests := []struct {
+		word      string
+		expected  string
+		pattern   string
+		knownRoot bool
+	}{
+		{"محبوب", "maḥbúb", "maf3ool", true},
+		{"تعليم", "ta'lím", "taf3eel", true},
+		{"قادر", "qádir", "fa3il", false},
+		{"استغفار", "istighfár", "istif3al", false},
+		{"أحوال", "aḥwál", "af3al", false}, // ranked above if3al by weight
+		{"ضمائر", "ḍamá'ir", "fa3a2il", false},
+		{"فقراء", "fuqará'", "fu3ala", true},
+		{"مؤمن", "mu'min", "muf3il", false}, // hamza seat folded into the root
+		{"رحم", "raḥm", "fa3l", true},
+	}
+
+	for _, tt := range tests {
+		candidates := trans.Vocalizations(tt.word)
+		if len(candidates) == 0 {
+			t.Errorf("Vocalizations(%s) returned no candidates", tt.word)
+			continue
+		}
+		best := candidates[0]
+		if best.Transliteration != tt.expected || best.Pattern != tt.pattern || best.KnownRoot != tt.knownRoot {
+			t.Errorf("Vocalizations(%s)[0] = %+v, expected %q from %s (known root %v)",
+				tt.word, best, tt.expected, tt.pattern, tt.knownRoot)
+		}
+	}
+
+	if got := trans.Transliterate("مقاصد", Arabic); got != "maqáṣid" {
+		t.Errorf("Expected pattern vocalization maqáṣid, got %s", got)
+	}
+
+	// A bare skeleton with an unknown root is left to the heuristic
+	if got := trans.vocalizeByPattern("قلب"); got != "" {
+		t.Errorf("Expected no pattern reading for an unknown root, got %q", got)
+	}
+}